@@ -2,41 +2,280 @@ package core
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/dlcuy22/endmi/extensions"
+	"github.com/dlcuy22/endmi/licenses"
 )
 
-// OutputHandler receives streaming lines from command execution.
-type OutputHandler func(line string)
-
 // App owns the project creation workflow.
 type App struct {
-	Output OutputHandler
+	Events EventHandler
+	// Toolchain is the Go installation used for all go commands. When nil,
+	// one is located with FindToolchain at the start of each creation.
+	Toolchain *Toolchain
+	// Verify runs build, vet and test on the new project once it is created.
+	Verify bool
+	// License, when set, is written to LICENSE for any template.
+	License *licenses.License
+	// Author is the copyright holder named in LICENSE and file headers.
+	Author string
+	// LicenseHeaders prepends an SPDX header to every generated .go file.
+	LicenseHeaders bool
+	// Customize, if set, can insert, replace or skip steps of the creation
+	// pipeline before it runs.
+	Customize func(p *Pipeline) error
+	// Context, if set, cancels running commands when it is done.
+	Context context.Context
+	// Vendor runs `go mod vendor` after tidying. Templates implementing
+	// extensions.VendorRecommender can turn it on; NoVendor overrides both.
+	Vendor   bool
+	NoVendor bool
+}
+
+// StepTiming records how long a single creation step took.
+type StepTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Report summarises a finished project creation.
+type Report struct {
+	Path    string
+	Timings []StepTiming
+	Total   time.Duration
+
+	// Verification holds the post-create checks when App.Verify is set.
+	Verification []VerifyResult
+	// VerifyLog is the log file holding the output of failed checks.
+	VerifyLog string
+	// Manifest is the generation manifest written to the project.
+	Manifest *Manifest
+
+	mu sync.Mutex
+}
+
+// ErrProjectExists is returned when the target directory already holds
+// files.
+var ErrProjectExists = errors.New("project already exists")
+
+// ProjectSpec describes where and how to create a project.
+type ProjectSpec struct {
+	// Name is the project name passed to the template.
+	Name string
+	// Dir is the directory to create. Defaults to Name, relative to the
+	// working directory.
+	Dir string
+	// ModulePath is given to `go mod init`. Defaults to Name.
+	ModulePath string
+	// Params are extra template parameters, recorded in the manifest and
+	// passed to templates implementing extensions.ParamTemplate.
+	Params map[string]string
 }
 
 // CreateProject scaffolds a project using the provided template.
-func (a App) CreateProject(t extensions.Template, projectName string) error {
-	projectPath := projectName
+func (a App) CreateProject(t extensions.Template, projectName string) (*Report, error) {
+	return a.Create(t, ProjectSpec{Name: projectName})
+}
+
+// Create scaffolds a project described by spec. The name and module path
+// are validated and the target directory must be missing or empty.
+func (a App) Create(t extensions.Template, spec ProjectSpec) (*Report, error) {
+	if spec.Dir == "" {
+		spec.Dir = spec.Name
+	}
+	if spec.ModulePath == "" {
+		spec.ModulePath = spec.Name
+	}
+
+	if err := ValidateProjectName(spec.Name); err != nil {
+		return nil, err
+	}
+	if err := ValidateModulePath(spec.ModulePath); err != nil {
+		return nil, err
+	}
+	if entries, err := os.ReadDir(spec.Dir); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%w: '%s' is not empty", ErrProjectExists, spec.Dir)
+	}
 
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
-		return err
+	return a.create(t, spec, nil)
+}
+
+// create runs the creation pipeline for spec. extend, if set, adjusts the
+// default pipeline before App.Customize does.
+func (a App) create(t extensions.Template, spec ProjectSpec, extend func(p *Pipeline) error) (*Report, error) {
+	start := time.Now()
+	a, err := a.withToolchain(t)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.renderFiles(t, spec.Name, spec.Params)
+	if err != nil {
+		return nil, err
 	}
 
-	baseDir := filepath.Join(projectPath, t.RootDir())
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return err
+	ctx := &BuildContext{
+		App:        a,
+		Template:   t,
+		Name:       spec.Name,
+		ModulePath: spec.ModulePath,
+		Path:       spec.Dir,
+		Params:     spec.Params,
+		Files:      files,
+		Report:     &Report{Path: spec.Dir},
+	}
+
+	p := DefaultPipeline(ctx)
+	if extend != nil {
+		if err := extend(p); err != nil {
+			return nil, err
+		}
+	}
+	if a.Customize != nil {
+		if err := a.Customize(p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.Run(ctx); err != nil {
+		return ctx.Report, err
+	}
+
+	ctx.Report.Total = time.Since(start)
+	return ctx.Report, nil
+}
+
+// DefaultPipeline returns the standard creation steps: prepare the
+// directory, `go mod init`, write files and fetch dependencies (together),
+// `go mod tidy`, `go mod vendor` if enabled, template hooks, the manifest
+// and, if enabled, verification.
+func DefaultPipeline(ctx *BuildContext) *Pipeline {
+	// `go get` rewrites go.mod and go.sum, so templates shipping their own
+	// must write them before dependencies are fetched.
+	concurrent := !touchesModFiles(ctx.Files)
+
+	return NewPipeline(
+		Step{Name: PhaseMkdir, Run: prepareDir},
+		Step{Name: PhaseModInit, Run: modInit},
+		Step{
+			Name:       PhaseWriteFiles,
+			Run:        writeFiles,
+			Items:      func(ctx *BuildContext) int { return len(ctx.Files) },
+			Concurrent: concurrent,
+		},
+		Step{
+			Name:       PhaseGetDeps,
+			Run:        fetchDependencies,
+			When:       func(ctx *BuildContext) bool { return len(ctx.Template.Dependencies()) > 0 },
+			Items:      func(ctx *BuildContext) int { return len(ctx.Template.Dependencies()) },
+			Concurrent: concurrent,
+		},
+		Step{Name: PhaseTidy, Run: modTidy},
+		Step{
+			Name: PhaseVendor,
+			Run:  modVendor,
+			When: func(ctx *BuildContext) bool { return ctx.App.vendors(ctx.Template) },
+		},
+		Step{
+			Name:  PhaseHooks,
+			Run:   runHooks,
+			When:  func(ctx *BuildContext) bool { return len(templateHooks(ctx.Template)) > 0 },
+			Items: func(ctx *BuildContext) int { return len(templateHooks(ctx.Template)) },
+		},
+		Step{Name: PhaseMetadata, Run: writeManifest},
+		Step{
+			Name:     PhaseVerify,
+			Run:      verify,
+			When:     func(ctx *BuildContext) bool { return ctx.App.Verify },
+			Items:    func(*BuildContext) int { return len(verifyChecks) },
+			Optional: true,
+		},
+	)
+}
+
+// withToolchain returns a copy of a whose toolchain is resolved and able to
+// build t.
+func (a App) withToolchain(t extensions.Template) (App, error) {
+	if a.Toolchain == nil {
+		tc, err := FindToolchain("")
+		if err != nil {
+			return a, err
+		}
+		a.Toolchain = tc
+	}
+
+	if err := a.Toolchain.Check(t); err != nil {
+		return a, err
+	}
+
+	if v, ok := t.(extensions.MinGoVersioner); ok && !a.Toolchain.Supports(v.MinGoVersion()) {
+		a.emit(Event{Kind: EventWarning, Line: fmt.Sprintf(
+			"%s is older than Go %s required by '%s'; go will download a newer toolchain",
+			a.Toolchain.Version, v.MinGoVersion(), t.Name(),
+		)})
+	}
+
+	return a, nil
+}
+
+func prepareDir(ctx *BuildContext) error {
+	if err := os.MkdirAll(filepath.Join(ctx.Path, ctx.Template.RootDir()), 0755); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
 	}
+	return nil
+}
+
+func modInit(ctx *BuildContext) error {
+	return ctx.App.runCommand(PhaseModInit, ctx.Path, "go", "mod", "init", ctx.ModulePath)
+}
+
+func writeFiles(ctx *BuildContext) error {
+	return writeTemplateFiles(ctx.Path, ctx.Files)
+}
 
-	if err := a.runCommandWithOutput("go", projectPath, "mod", "init", projectName); err != nil {
-		return err
+func modTidy(ctx *BuildContext) error {
+	return ctx.App.runCommand(PhaseTidy, ctx.Path, "go", "mod", "tidy")
+}
+
+func modVendor(ctx *BuildContext) error {
+	return ctx.App.runCommand(PhaseVendor, ctx.Path, "go", "mod", "vendor")
+}
+
+// vendors reports whether the project should be vendored: on request, or
+// when the template recommends it, unless NoVendor is set.
+func (a App) vendors(t extensions.Template) bool {
+	if a.NoVendor {
+		return false
+	}
+	if a.Vendor {
+		return true
 	}
+	r, ok := t.(extensions.VendorRecommender)
+	return ok && r.RecommendVendor()
+}
 
-	for rel, content := range t.Files(projectName) {
-		fullPath := filepath.Join(baseDir, rel)
+// touchesModFiles reports whether any rendered file is the module's go.mod
+// or go.sum.
+func touchesModFiles(files map[string]string) bool {
+	_, mod := files["go.mod"]
+	_, sum := files["go.sum"]
+	return mod || sum
+}
+
+// writeTemplateFiles writes files keyed by slash-separated paths relative
+// to baseDir, creating parent directories as needed.
+func writeTemplateFiles(baseDir string, files map[string]string) error {
+	for rel, content := range files {
+		fullPath := filepath.Join(baseDir, filepath.FromSlash(rel))
 		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 			return err
 		}
@@ -44,49 +283,165 @@ func (a App) CreateProject(t extensions.Template, projectName string) error {
 			return err
 		}
 	}
+	return nil
+}
 
-	for _, dep := range t.Dependencies() {
-		if err := a.runCommandWithOutput("go", projectPath, "get", dep); err != nil {
-			return err
+// fetchDependencies resolves all modules with a single `go get`. If that
+// fails, each module is fetched on its own so the error names the culprit.
+func fetchDependencies(ctx *BuildContext) error {
+	deps := ctx.Template.Dependencies()
+	if len(deps) == 0 {
+		return nil
+	}
+
+	args := append([]string{"get"}, deps...)
+	batchErr := ctx.App.runCommand(PhaseGetDeps, ctx.Path, "go", args...)
+	if batchErr == nil {
+		return nil
+	}
+
+	for i, dep := range deps {
+		err := ctx.App.runCommandItem(PhaseGetDeps, i+1, len(deps), ctx.Path, "go", "get", dep)
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", dep, err)
 		}
 	}
 
-	if err := a.runCommandWithOutput("go", projectPath, "mod", "tidy"); err != nil {
-		return err
+	return nil
+}
+
+// templateHooks returns the template's post-create hooks, if it declares any.
+func templateHooks(t extensions.Template) []extensions.Hook {
+	if provider, ok := t.(extensions.HookProvider); ok {
+		return provider.Hooks()
 	}
+	return nil
+}
 
+// runHooks runs the template's post-create hooks in order.
+func runHooks(ctx *BuildContext) error {
+	hooks := templateHooks(ctx.Template)
+	for i, hook := range hooks {
+		if len(hook.Command) == 0 {
+			continue
+		}
+		err := ctx.App.runCommandItem(PhaseHooks, i+1, len(hooks), ctx.Path, hook.Command[0], hook.Command[1:]...)
+		if err != nil {
+			return fmt.Errorf("hook %q failed: %w", hook.Name, err)
+		}
+	}
 	return nil
 }
 
-func (a App) runCommandWithOutput(name string, dir string, args ...string) error {
-	cmd := exec.Command(name, args...)
+func (a App) runCommand(phase Phase, dir string, name string, args ...string) error {
+	return a.runCommandItem(phase, 0, 0, dir, name, args...)
+}
+
+// runCommandItem runs a command in dir, streaming its output as events
+// tagged with phase and the item position within it.
+func (a App) runCommandItem(phase Phase, index, total int, dir string, name string, args ...string) error {
+	_, err := a.captureCommand(phase, index, total, dir, name, args...)
+	return err
+}
+
+// command prepares name in dir, resolving "go" to the selected toolchain and
+// binding it to the App's context.
+func (a App) command(dir string, name string, args ...string) *exec.Cmd {
+	if name == "go" && a.Toolchain != nil {
+		name = a.Toolchain.Path
+	}
+	ctx := a.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = dir
+	if a.Toolchain != nil {
+		cmd.Env = a.Toolchain.environ()
+	}
+	return cmd
+}
+
+// captureCommand is runCommandItem that also returns the combined output
+// lines, in the order they were read.
+func (a App) captureCommand(phase Phase, index, total int, dir string, name string, args ...string) ([]string, error) {
+	argv := append([]string{name}, args...)
+	base := Event{Phase: phase, Index: index, Total: total, Command: argv}
+
+	cmd := a.command(dir, name, args...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	started := base
+	started.Kind = EventCommandStarted
+	a.emit(started)
+
+	start := time.Now()
 	if err := cmd.Start(); err != nil {
-		return err
+		finished := base
+		finished.Kind = EventCommandFinished
+		finished.ExitCode = -1
+		finished.Err = err
+		a.emit(finished)
+		return nil, err
 	}
 
-	go a.streamOutput(stdout)
-	go a.streamOutput(stderr)
+	// Both pipes must be drained before Wait, otherwise a chatty command can
+	// block on a full pipe and Wait can close it mid-read.
+	var mu sync.Mutex
+	var lines []string
+	collect := func(line string) {
+		mu.Lock()
+		lines = append(lines, line)
+		mu.Unlock()
+	}
 
-	return cmd.Wait()
-}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.streamOutput(base, StreamStdout, stdout, collect)
+	}()
+	go func() {
+		defer wg.Done()
+		a.streamOutput(base, StreamStderr, stderr, collect)
+	}()
+	wg.Wait()
 
-func (a App) streamOutput(r io.Reader) {
-	if a.Output == nil {
-		return
+	err = cmd.Wait()
+
+	finished := base
+	finished.Kind = EventCommandFinished
+	finished.Duration = time.Since(start)
+	finished.Err = err
+	finished.ExitCode = cmd.ProcessState.ExitCode()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		finished.ExitCode = exitErr.ExitCode()
 	}
+	a.emit(finished)
+
+	return lines, err
+}
+
+func (a App) streamOutput(base Event, stream Stream, r io.Reader, collect func(string)) {
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		a.Output(scanner.Text())
+		collect(scanner.Text())
+
+		e := base
+		e.Kind = EventOutput
+		e.Stream = stream
+		e.Line = scanner.Text()
+		a.emit(e)
 	}
+	// Keep draining if a line was too long for the scanner.
+	io.Copy(io.Discard, r)
 }
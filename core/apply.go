@@ -0,0 +1,247 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dlcuy22/endmi/extensions"
+	"github.com/dlcuy22/endmi/licenses"
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// defaultStackConcurrency is used when a stack file sets no concurrency.
+const defaultStackConcurrency = 4
+
+// Stack is a declarative list of projects to create together, as read from
+// a stack file by `endmi apply`.
+type Stack struct {
+	// Concurrency caps how many projects are created at once.
+	Concurrency int            `json:"concurrency"`
+	Projects    []StackProject `json:"projects"`
+
+	// dir is the stack file's directory; project dirs are relative to it.
+	dir string
+}
+
+// StackProject describes one project in a stack file. Unset add-ons fall
+// back to the App's defaults.
+type StackProject struct {
+	Name           string            `json:"name"`
+	Template       string            `json:"template"`
+	Module         string            `json:"module"`
+	Dir            string            `json:"dir"`
+	Params         map[string]string `json:"params"`
+	License        string            `json:"license"`
+	LicenseHeaders *bool             `json:"license_headers"`
+	Vendor         *bool             `json:"vendor"`
+	Verify         *bool             `json:"verify"`
+}
+
+// ApplyStatus is the outcome for one stack project.
+type ApplyStatus string
+
+const (
+	ApplyCreated ApplyStatus = "created"
+	ApplySkipped ApplyStatus = "skipped"
+	ApplyFailed  ApplyStatus = "failed"
+)
+
+// ApplyResult reports what happened to one stack project.
+type ApplyResult struct {
+	Project StackProject
+	Dir     string
+	Status  ApplyStatus
+	Report  *Report
+	// Err is why a project failed, or why a directory endmi did not
+	// create was skipped.
+	Err      error
+	Duration time.Duration
+}
+
+// LoadStack reads a stack file and validates every project before any work
+// is done, so a typo doesn't leave half a stack behind.
+func LoadStack(path string) (*Stack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack file: %w", err)
+	}
+
+	var stack Stack
+	if err := json.Unmarshal(data, &stack); err != nil {
+		return nil, fmt.Errorf("failed to parse stack file: %w", err)
+	}
+	stack.dir = filepath.Dir(path)
+
+	if len(stack.Projects) == 0 {
+		return nil, errors.New("stack file lists no projects")
+	}
+
+	seen := map[string]string{}
+	for i, p := range stack.Projects {
+		if err := ValidateProjectName(p.Name); err != nil {
+			return nil, fmt.Errorf("project %d: %w", i+1, err)
+		}
+		if p.Module != "" {
+			if err := ValidateModulePath(p.Module); err != nil {
+				return nil, fmt.Errorf("project '%s': %w", p.Name, err)
+			}
+		}
+		if _, err := utils.FindTemplateByName(extensions.BuiltinTemplates(), p.Template); err != nil {
+			return nil, fmt.Errorf("project '%s': %w", p.Name, err)
+		}
+		if p.License != "" && p.License != "none" {
+			if _, err := licenses.Find(p.License); err != nil {
+				return nil, fmt.Errorf("project '%s': %w", p.Name, err)
+			}
+		}
+
+		dir := stack.ProjectDir(p)
+		if other, ok := seen[dir]; ok {
+			return nil, fmt.Errorf("projects '%s' and '%s' both target %s", other, p.Name, dir)
+		}
+		seen[dir] = p.Name
+	}
+
+	return &stack, nil
+}
+
+// ProjectDir returns where p is created: its dir, or its name, relative to
+// the stack file.
+func (s *Stack) ProjectDir(p StackProject) string {
+	dir := p.Dir
+	if dir == "" {
+		dir = p.Name
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(s.dir, dir)
+}
+
+// ApplyStack creates every project in the stack, at most Concurrency at a
+// time. Projects whose directory already holds files are skipped, so
+// re-running a stack only creates what is missing. onDone, if set, is called
+// as each project finishes. Results are returned in stack order.
+func (a App) ApplyStack(stack *Stack, onDone func(ApplyResult)) []ApplyResult {
+	limit := stack.Concurrency
+	if limit <= 0 {
+		limit = defaultStackConcurrency
+	}
+
+	results := make([]ApplyResult, len(stack.Projects))
+	sem := make(chan struct{}, limit)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, p := range stack.Projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := a.applyProject(stack, p)
+			results[i] = result
+
+			if onDone != nil {
+				mu.Lock()
+				onDone(result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (a App) applyProject(stack *Stack, p StackProject) ApplyResult {
+	start := time.Now()
+	result := ApplyResult{Project: p, Dir: stack.ProjectDir(p)}
+
+	// The manifest is written once every other step has succeeded, so a
+	// directory holding one is a finished project. Other non-empty
+	// directories, made by hand or before endmi wrote manifests, are left
+	// alone too, but say why; a failed run of this stack clears what it
+	// wrote, so they are not its leftovers.
+	if _, err := os.Stat(filepath.Join(result.Dir, ManifestFile)); err == nil {
+		result.Status = ApplySkipped
+		return result
+	}
+	entries, err := os.ReadDir(result.Dir)
+	if err == nil && len(entries) > 0 {
+		result.Status = ApplySkipped
+		result.Err = fmt.Errorf("%s is not empty but has no %s, so it was not created by endmi", result.Dir, ManifestFile)
+		return result
+	}
+	existed := err == nil
+
+	if p.License != "" {
+		a.License = nil
+		if p.License != "none" {
+			l, _ := licenses.Find(p.License) // checked by LoadStack
+			a.License = &l
+		}
+	}
+	if p.LicenseHeaders != nil {
+		a.LicenseHeaders = *p.LicenseHeaders
+	}
+	if p.Vendor != nil {
+		a.Vendor = *p.Vendor
+		a.NoVendor = !*p.Vendor
+	}
+	if p.Verify != nil {
+		a.Verify = *p.Verify
+	}
+
+	t, _ := utils.FindTemplateByName(extensions.BuiltinTemplates(), p.Template) // checked by LoadStack
+	report, err := a.Create(t, ProjectSpec{
+		Name:       p.Name,
+		Dir:        result.Dir,
+		ModulePath: p.Module,
+		Params:     p.Params,
+	})
+
+	result.Report = report
+	result.Duration = time.Since(start)
+	switch {
+	case errors.Is(err, ErrProjectExists):
+		result.Status = ApplySkipped
+	case err != nil:
+		result.Status = ApplyFailed
+		result.Err = err
+		// Clear the partial project so the next run starts over.
+		if cleanErr := removePartial(result.Dir, existed); cleanErr != nil {
+			result.Err = fmt.Errorf("%w (removing the partial project failed: %v)", err, cleanErr)
+		}
+	case !report.Verified():
+		result.Status = ApplyFailed
+		result.Err = fmt.Errorf("%w, see %s", ErrVerifyFailed, report.VerifyLog)
+	default:
+		result.Status = ApplyCreated
+	}
+	return result
+}
+
+// removePartial deletes what a failed creation left in dir, keeping dir
+// itself if it existed, empty, beforehand.
+func removePartial(dir string, existed bool) error {
+	if !existed {
+		return os.RemoveAll(dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CloneTempProject copies a temp project into a new one named newName, or
+// "<name>-2", "<name>-3", ... when newName is empty. The copy gets its own
+// module path, the new name, with the project's imports of its own packages
+// rewritten to match; a scratch snippet is copied into the scratch module.
+// Tags, note and TTL are carried over and Parent records the source, while
+// pins and snapshots stay with the original.
+func (tcm *TempCodeManager) CloneTempProject(projectName, newName string) (TempProjectMetadata, error) {
+	source, err := tcm.ResolveTempProject(projectName)
+	if err != nil {
+		return TempProjectMetadata{}, err
+	}
+
+	if newName == "" {
+		newName = tcm.cloneName(source.Name)
+	}
+	if err := ValidateProjectName(newName); err != nil {
+		return TempProjectMetadata{}, err
+	}
+	if _, err := tcm.lookupTempProject(newName); err == nil {
+		return TempProjectMetadata{}, fmt.Errorf("%w: temp project '%s'", ErrProjectExists, newName)
+	}
+	if err := tcm.checkQuota(); err != nil {
+		return TempProjectMetadata{}, err
+	}
+
+	target := filepath.Join(filepath.Dir(source.Path), newName)
+	if err := copyTree(source.Path, target, isTempMetadata); err != nil {
+		os.RemoveAll(target)
+		return TempProjectMetadata{}, fmt.Errorf("failed to copy '%s': %w", source.Name, err)
+	}
+
+	var unparsed []string
+	if source.Scratch {
+		unparsed, err = rewriteImports(target, scratchModule+"/"+source.Name, scratchModule+"/"+newName)
+	} else if _, statErr := os.Stat(filepath.Join(target, "go.mod")); statErr == nil {
+		unparsed, err = RewriteModulePath(target, newName)
+	}
+	if err != nil {
+		os.RemoveAll(target)
+		return TempProjectMetadata{}, fmt.Errorf("failed to rename the module of '%s': %w", newName, err)
+	}
+	tcm.App.warnUnparsed(unparsed)
+
+	clone := TempProjectMetadata{
+		Name:      newName,
+		CreatedAt: time.Now(),
+		Template:  source.Template,
+		Path:      target,
+		TTL:       source.TTL,
+		Scratch:   source.Scratch,
+		Tags:      source.Tags,
+		Note:      source.Note,
+		Parent:    source.Name,
+	}
+	if err := tcm.saveMetadata(target, clone); err != nil {
+		os.RemoveAll(target)
+		return TempProjectMetadata{}, err
+	}
+	return clone, nil
+}
+
+// cloneName returns the first of "<name>-2", "<name>-3", ... that no temp
+// project uses yet.
+func (tcm *TempCodeManager) cloneName(name string) string {
+	for i := 2; ; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		base := name
+		if len(base)+len(suffix) > maxNameLength {
+			base = base[:maxNameLength-len(suffix)]
+		}
+		candidate := base + suffix
+		if _, err := tcm.lookupTempProject(candidate); err != nil {
+			return candidate
+		}
+	}
+}
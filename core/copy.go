@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// moveTree moves the directory src to dst. When a rename is impossible,
+// typically because dst is on another filesystem, src is copied, the copy
+// verified, and only then src removed.
+func moveTree(src, dst string) error {
+	renameErr := os.Rename(src, dst)
+	if renameErr == nil {
+		return nil
+	}
+
+	if err := copyTree(src, dst, nil); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to move project: %v; copy fallback failed: %w", renameErr, err)
+	}
+	if err := verifyTree(src, dst, nil); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to move project: copy differs from the original: %w", err)
+	}
+	return os.RemoveAll(src)
+}
+
+// copyTree copies the directory src to dst, keeping file modes and
+// modification times and copying symlinks as links. skip, if set, filters
+// out slash-separated paths relative to src.
+func copyTree(src, dst string, skip func(rel string) bool) error {
+	// Directory modes are applied last, so read-only directories can still
+	// be filled.
+	type dirMode struct {
+		path string
+		mode fs.FileMode
+	}
+	var dirs []dirMode
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && skip != nil && skip(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			dirs = append(dirs, dirMode{target, info.Mode().Perm()})
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chtimes(target, info.ModTime(), info.ModTime())
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type %s", rel, info.Mode().Type())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies a regular file, creating dst with mode.
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile's mode is subject to the umask.
+	return os.Chmod(dst, mode)
+}
+
+// verifyTree checks that dst holds the same entries as src, with the same
+// modes, symlink targets and file contents. skip is the filter given to
+// copyTree.
+func verifyTree(src, dst string, skip func(rel string) bool) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && skip != nil && skip(filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		want, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		got, err := os.Lstat(filepath.Join(dst, rel))
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		if want.Mode() != got.Mode() {
+			return fmt.Errorf("%s: mode %s, want %s", rel, got.Mode(), want.Mode())
+		}
+
+		switch {
+		case want.Mode()&fs.ModeSymlink != 0:
+			wantLink, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			gotLink, err := os.Readlink(filepath.Join(dst, rel))
+			if err != nil {
+				return err
+			}
+			if wantLink != gotLink {
+				return fmt.Errorf("%s: links to %s, want %s", rel, gotLink, wantLink)
+			}
+		case want.Mode().IsRegular():
+			if want.Size() != got.Size() {
+				return fmt.Errorf("%s: size %d, want %d", rel, got.Size(), want.Size())
+			}
+			wantSum, err := checksumFile(path)
+			if err != nil {
+				return err
+			}
+			gotSum, err := checksumFile(filepath.Join(dst, rel))
+			if err != nil {
+				return err
+			}
+			if wantSum != gotSum {
+				return fmt.Errorf("%s: contents differ", rel)
+			}
+		}
+		return nil
+	})
+}
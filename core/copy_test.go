@@ -0,0 +1,150 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestTree creates a small project tree with nested and read-only
+// directories, an executable and a symlink.
+func writeTestTree(t *testing.T, root string) {
+	t.Helper()
+	files := map[string]string{
+		"go.mod":                "module example.com/ab\n",
+		"main.go":               "package main\n",
+		"internal/db/db.go":     "package db\n",
+		"vendor/lib/lib.go":     "package lib\n",
+		".endmi-temp.json":      "{}\n",
+		"readonly/settings.txt": "x\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "run.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(root, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "main.go"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "readonly"), 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(filepath.Join(root, "readonly"), 0755) })
+}
+
+func TestCopyTree(t *testing.T) {
+	skipVendor := func(rel string) bool { return rel == "vendor" || rel == ".endmi-temp.json" }
+
+	tests := []struct {
+		name     string
+		skip     func(string) bool
+		wantGone []string
+	}{
+		{name: "everything"},
+		{name: "with skip", skip: skipVendor, wantGone: []string{"vendor", ".endmi-temp.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join(t.TempDir(), "src")
+			dst := filepath.Join(t.TempDir(), "dst")
+			writeTestTree(t, src)
+			t.Cleanup(func() { os.Chmod(filepath.Join(dst, "readonly"), 0755) })
+
+			if err := copyTree(src, dst, tt.skip); err != nil {
+				t.Fatalf("copyTree: %v", err)
+			}
+			if err := verifyTree(src, dst, tt.skip); err != nil {
+				t.Errorf("verifyTree after copyTree: %v", err)
+			}
+
+			for _, rel := range tt.wantGone {
+				if _, err := os.Lstat(filepath.Join(dst, rel)); !os.IsNotExist(err) {
+					t.Errorf("%s was copied although skipped", rel)
+				}
+			}
+			want, _ := os.Stat(filepath.Join(src, "main.go"))
+			got, err := os.Stat(filepath.Join(dst, "main.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.ModTime().Equal(want.ModTime()) {
+				t.Errorf("main.go modified %v, want %v", got.ModTime(), want.ModTime())
+			}
+		})
+	}
+}
+
+func TestVerifyTreeFindsDifferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(dst string) error
+		wantErr string
+	}{
+		{
+			name: "contents",
+			change: func(dst string) error {
+				return os.WriteFile(filepath.Join(dst, "go.mod"), []byte("module example.com/xy\n"), 0644)
+			},
+			wantErr: "go.mod: contents differ",
+		},
+		{
+			name: "size",
+			change: func(dst string) error {
+				return os.WriteFile(filepath.Join(dst, "main.go"), []byte("package main\n\n"), 0644)
+			},
+			wantErr: "main.go: size",
+		},
+		{
+			name:    "mode",
+			change:  func(dst string) error { return os.Chmod(filepath.Join(dst, "run.sh"), 0644) },
+			wantErr: "run.sh: mode",
+		},
+		{
+			name:    "missing file",
+			change:  func(dst string) error { return os.Remove(filepath.Join(dst, "internal", "db", "db.go")) },
+			wantErr: "internal/db/db.go",
+		},
+		{
+			name: "symlink target",
+			change: func(dst string) error {
+				if err := os.Remove(filepath.Join(dst, "link.go")); err != nil {
+					return err
+				}
+				return os.Symlink("go.mod", filepath.Join(dst, "link.go"))
+			},
+			wantErr: "link.go: links to go.mod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join(t.TempDir(), "src")
+			dst := filepath.Join(t.TempDir(), "dst")
+			writeTestTree(t, src)
+			t.Cleanup(func() { os.Chmod(filepath.Join(dst, "readonly"), 0755) })
+			if err := copyTree(src, dst, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.change(dst); err != nil {
+				t.Fatal(err)
+			}
+			err := verifyTree(src, dst, nil)
+			if err == nil || !strings.Contains(filepath.ToSlash(err.Error()), tt.wantErr) {
+				t.Errorf("verifyTree = %v, want an error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"time"
+)
+
+// Phase identifies a stage of project creation.
+type Phase string
+
+const (
+	PhaseMkdir      Phase = "mkdir"
+	PhaseModInit    Phase = "mod init"
+	PhaseWriteFiles Phase = "write files"
+	PhaseGetDeps    Phase = "go get"
+	PhaseTidy       Phase = "mod tidy"
+	PhaseVendor     Phase = "mod vendor"
+	PhaseHooks      Phase = "hooks"
+	PhaseVerify     Phase = "verify"
+	PhaseMetadata   Phase = "metadata"
+	// PhaseTempMetadata saves .endmi_meta.json for temp projects.
+	PhaseTempMetadata Phase = "temp metadata"
+	// PhaseBuild builds the target module after `temp promote --into`.
+	PhaseBuild Phase = "build"
+)
+
+// EventKind tells what an Event reports.
+type EventKind int
+
+const (
+	// EventPhaseStarted is sent when a phase begins.
+	EventPhaseStarted EventKind = iota
+	// EventPhaseFinished is sent when a phase ends; Err is set on failure.
+	EventPhaseFinished
+	// EventCommandStarted is sent before an external command runs.
+	EventCommandStarted
+	// EventCommandFinished is sent after an external command exits.
+	EventCommandFinished
+	// EventOutput carries a single line of command output.
+	EventOutput
+	// EventWarning carries a non-fatal problem in Line.
+	EventWarning
+)
+
+// Stream identifies which output stream a line came from.
+type Stream int
+
+const (
+	StreamStdout Stream = iota
+	StreamStderr
+)
+
+// Event is a single progress notification emitted by App.
+type Event struct {
+	Kind  EventKind
+	Phase Phase
+
+	// Index and Total count items within a phase, e.g. dependency 2 of 3.
+	// Both are zero when the phase has no items.
+	Index int
+	Total int
+
+	// Command is the argv of the command for command and output events.
+	Command  []string
+	ExitCode int
+
+	// Stream and Line are set for EventOutput; Line also carries the
+	// message of EventWarning.
+	Stream Stream
+	Line   string
+
+	// Duration is set for finished events.
+	Duration time.Duration
+	Err      error
+}
+
+// EventHandler receives progress events. It may be called from several
+// goroutines at once.
+type EventHandler func(Event)
+
+func (a App) emit(e Event) {
+	if a.Events != nil {
+		a.Events(e)
+	}
+}
+
+// runPhase emits started/finished events around fn and records its duration
+// in report.
+func (a App) runPhase(report *Report, phase Phase, total int, fn func() error) error {
+	a.emit(Event{Kind: EventPhaseStarted, Phase: phase, Total: total})
+
+	start := time.Now()
+	err := fn()
+	duration := time.Since(start)
+
+	report.mu.Lock()
+	report.Timings = append(report.Timings, StepTiming{Name: string(phase), Duration: duration})
+	report.mu.Unlock()
+
+	a.emit(Event{Kind: EventPhaseFinished, Phase: phase, Total: total, Duration: duration, Err: err})
+	return err
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// TTLFor returns how long a temp project lives: its own TTL, else the
+// configured TempTTL. Zero means it never expires.
+func (tcm *TempCodeManager) TTLFor(p TempProjectMetadata) (time.Duration, error) {
+	ttl := p.TTL
+	if ttl == "" {
+		cfg, err := tcm.config()
+		if err != nil {
+			return 0, err
+		}
+		ttl = cfg.TempTTL
+	}
+	if ttl == "" {
+		return 0, nil
+	}
+	return utils.ParseDuration(ttl)
+}
+
+// ExpiresAt returns when a temp project expires, counted from the later of
+// its creation and last use, and false if it never does.
+func (tcm *TempCodeManager) ExpiresAt(p TempProjectMetadata) (time.Time, bool, error) {
+	if p.Pinned {
+		return time.Time{}, false, nil
+	}
+	ttl, err := tcm.TTLFor(p)
+	if err != nil || ttl == 0 {
+		return time.Time{}, false, err
+	}
+
+	return p.LastUsed().Add(ttl), true, nil
+}
+
+// ExpiredProjects returns the temp projects whose TTL has passed at now.
+// Pinned projects never expire.
+func (tcm *TempCodeManager) ExpiredProjects(now time.Time) ([]TempProjectMetadata, error) {
+	projects, err := tcm.ListTempProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []TempProjectMetadata
+	for _, p := range projects {
+		if p.Pinned {
+			continue
+		}
+		expiresAt, ok, err := tcm.ExpiresAt(p)
+		if err != nil {
+			return nil, fmt.Errorf("project '%s': %w", p.Name, err)
+		}
+		if ok && now.After(expiresAt) {
+			expired = append(expired, p)
+		}
+	}
+	return expired, nil
+}
+
+// GC removes expired temp projects and returns them. With dryRun, nothing is
+// removed.
+func (tcm *TempCodeManager) GC(dryRun bool) ([]TempProjectMetadata, error) {
+	expired, err := tcm.ExpiredProjects(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return expired, nil
+	}
+
+	for _, p := range expired {
+		if err := tcm.removeProject(p); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", p.Name, err)
+		}
+	}
+	return expired, nil
+}
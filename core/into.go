@@ -0,0 +1,324 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PromoteIntoPackage turns a temp project into a package of an existing
+// module at targetDir: every .go file except those declaring func main is
+// copied there with its package clause and imports rewritten, the temp
+// project's requirements are added to the module with `go get`, and the
+// module is built to confirm it works. The temp project is removed only once
+// the build passes; if any step fails, the copied files are deleted and the
+// module's go.mod and go.sum restored.
+func (tcm *TempCodeManager) PromoteIntoPackage(projectName, targetDir string) (*Report, error) {
+	start := time.Now()
+	project, err := tcm.ResolveTempProjectStrict(projectName)
+	if err != nil {
+		return nil, err
+	}
+	app, err := tcm.App.withDefaultToolchain()
+	if err != nil {
+		return nil, err
+	}
+
+	targetDir, err = filepath.Abs(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	modRoot, modulePath, err := findModule(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(modRoot, targetDir)
+	if err != nil {
+		return nil, err
+	}
+	importPath := modulePath
+	if rel != "." {
+		importPath = path.Join(modulePath, filepath.ToSlash(rel))
+	}
+
+	packageName, err := targetPackageName(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	tempModule := project.Name
+	if project.Scratch {
+		tempModule = scratchModule + "/" + project.Name
+	} else if m, err := readModulePath(project.Path); err == nil {
+		tempModule = m
+	}
+
+	files, err := packageFiles(project.Path, tempModule, importPath, packageName)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("temp project '%s' has no code outside func main to promote", project.Name)
+	}
+	var conflicts []string
+	for rel := range files {
+		if _, err := os.Stat(filepath.Join(targetDir, filepath.FromSlash(rel))); err == nil {
+			conflicts = append(conflicts, rel)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("files already exist in %s: %s", targetDir, strings.Join(conflicts, ", "))
+	}
+
+	modDir := project.Path
+	if project.Scratch {
+		modDir = filepath.Dir(project.Path)
+	}
+	required, err := requiredModules(modDir)
+	if err != nil {
+		return nil, err
+	}
+	requires := modulesFor(fileImports(files), required)
+
+	// Remember the target's state so a failed step can be undone: the files
+	// are all new (conflicts were refused above), as are the directories
+	// that do not exist yet, and `go get` edits go.mod and go.sum.
+	createdDirs := missingDirs(targetDir, files)
+	modFiles, err := readModFiles(modRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &BuildContext{
+		App:        app,
+		Name:       project.Name,
+		ModulePath: importPath,
+		Path:       targetDir,
+		Files:      files,
+		Report:     &Report{Path: targetDir},
+	}
+	p := NewPipeline(
+		Step{
+			Name: PhaseMkdir,
+			Run: func(ctx *BuildContext) error {
+				return os.MkdirAll(ctx.Path, 0755)
+			},
+		},
+		Step{
+			Name:  PhaseWriteFiles,
+			Run:   writeFiles,
+			Items: func(ctx *BuildContext) int { return len(ctx.Files) },
+		},
+		Step{
+			Name: PhaseGetDeps,
+			Run: func(ctx *BuildContext) error {
+				args := append([]string{"get"}, requires...)
+				return ctx.App.runCommand(PhaseGetDeps, modRoot, "go", args...)
+			},
+			When: func(*BuildContext) bool { return len(requires) > 0 },
+		},
+		Step{
+			Name: PhaseBuild,
+			Run: func(ctx *BuildContext) error {
+				pattern := "./..."
+				if rel != "." {
+					pattern = "./" + filepath.ToSlash(rel) + "/..."
+				}
+				return ctx.App.runCommand(PhaseBuild, modRoot, "go", "build", pattern)
+			},
+		},
+	)
+	if err := p.Run(ctx); err != nil {
+		if undoErr := undoInto(targetDir, files, createdDirs, modRoot, modFiles); undoErr != nil {
+			return ctx.Report, fmt.Errorf("%w (temp project '%s' was kept, but undoing the changes to %s failed: %v)",
+				err, project.Name, modRoot, undoErr)
+		}
+		return ctx.Report, fmt.Errorf("%w (temp project '%s' was kept and %s left as it was)", err, project.Name, modRoot)
+	}
+
+	if err := tcm.removeProject(project); err != nil {
+		return ctx.Report, err
+	}
+	ctx.Report.Total = time.Since(start)
+	return ctx.Report, nil
+}
+
+// missingDirs returns the outermost directories that writing files under
+// targetDir would create, targetDir itself included.
+func missingDirs(targetDir string, files map[string]string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for rel := range files {
+		dir := targetDir
+		for _, elem := range append([]string{""}, strings.Split(path.Dir(rel), "/")...) {
+			if elem != "" && elem != "." {
+				dir = filepath.Join(dir, elem)
+			}
+			if _, err := os.Stat(dir); err == nil {
+				continue
+			}
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, dir)
+			}
+			break
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// readModFiles saves go.mod and go.sum of the module at root; a file that
+// does not exist is recorded as nil.
+func readModFiles(root string) (map[string][]byte, error) {
+	saved := make(map[string][]byte)
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		saved[name] = data
+	}
+	return saved, nil
+}
+
+// undoInto removes what a failed PromoteIntoPackage wrote and puts go.mod
+// and go.sum back as they were.
+func undoInto(targetDir string, files map[string]string, createdDirs []string, modRoot string, modFiles map[string][]byte) error {
+	var errs []error
+	for rel := range files {
+		if err := os.Remove(filepath.Join(targetDir, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	for _, dir := range createdDirs {
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for name, data := range modFiles {
+		p := filepath.Join(modRoot, name)
+		var err error
+		if data == nil {
+			err = os.Remove(p)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = os.WriteFile(p, data, 0644)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fileImports returns every import path used by the given Go files.
+func fileImports(files map[string]string) []string {
+	var imports []string
+	fset := token.NewFileSet()
+	for name, src := range files {
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				imports = append(imports, p)
+			}
+		}
+	}
+	return imports
+}
+
+// targetPackageName returns the package name used by the .go files already
+// in dir, or one derived from the directory name.
+func targetPackageName(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, e.Name()), nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name, nil
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(dir)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name == "" || name[0] >= '0' && name[0] <= '9' || name == "main" {
+		name = "pkg" + name
+	}
+	return name, nil
+}
+
+// packageFiles reads the temp project's .go files for promotion into a
+// package: files declaring func main and main packages below the root are
+// left out, root files are renamed to packageName and imports of the temp
+// module are moved under importPath.
+func packageFiles(projectDir, tempModule, importPath, packageName string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != projectDir && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), p, src, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(projectDir, p)
+		if err != nil {
+			return err
+		}
+		root := filepath.Dir(rel) == "."
+		if declaresMain(f) || (!root && f.Name.Name == "main") {
+			return nil
+		}
+
+		rename := ""
+		if root {
+			rename = packageName
+		}
+		out, err := rewriteGoFile(src, tempModule, importPath, rename)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(out)
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dlcuy22/endmi/extensions"
+)
+
+// LicenseFile is where the chosen license is written, relative to the
+// project root.
+const LicenseFile = "LICENSE"
+
+// renderFiles returns every file to generate keyed by its slash-separated
+// path relative to the project root: the template files under RootDir, with
+// SPDX headers when enabled, plus the LICENSE file.
+func (a App) renderFiles(t extensions.Template, projectName string, params map[string]string) (map[string]string, error) {
+	year := time.Now().Year()
+	files := map[string]string{}
+
+	templateFiles := t.Files(projectName)
+	if pt, ok := t.(extensions.ParamTemplate); ok {
+		templateFiles = pt.FilesWithParams(projectName, params)
+	}
+
+	for rel, content := range templateFiles {
+		path := filepath.ToSlash(filepath.Join(t.RootDir(), rel))
+		if a.License != nil && a.LicenseHeaders && strings.HasSuffix(path, ".go") {
+			content = a.License.Header(a.Author, year) + content
+		}
+		files[path] = content
+	}
+
+	if a.License != nil {
+		text, err := a.License.Render(a.Author, year)
+		if err != nil {
+			return nil, err
+		}
+		files[LicenseFile] = text
+	}
+
+	return files, nil
+}
+
+// addOns lists the template-independent extras applied to the project, as
+// recorded in the manifest.
+func (a App) addOns(t extensions.Template) []string {
+	addOns := []string{}
+	if a.vendors(t) {
+		addOns = append(addOns, "vendor")
+	}
+	if a.License != nil {
+		addOns = append(addOns, "license:"+a.License.ID)
+		if a.LicenseHeaders {
+			addOns = append(addOns, "spdx-headers")
+		}
+	}
+	return addOns
+}
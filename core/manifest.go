@@ -0,0 +1,172 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dlcuy22/endmi/extensions"
+)
+
+// Version is the endmi release recorded in generation manifests.
+const Version = "0.1.0"
+
+// ManifestFile is the generation manifest path, relative to the project root.
+const ManifestFile = ".endmi/manifest.json"
+
+// ErrNoManifest is returned by LoadManifest for projects endmi did not
+// create.
+var ErrNoManifest = errors.New("it was not created by endmi")
+
+// Manifest records how a project was generated so later runs can tell which
+// generated files were changed by hand.
+type Manifest struct {
+	Template        string            `json:"template"`
+	TemplateVersion string            `json:"template_version"`
+	Params          map[string]string `json:"params"`
+	AddOns          []string          `json:"add_ons"`
+	EndmiVersion    string            `json:"endmi_version"`
+	GoVersion       string            `json:"go_version"`
+	CreatedAt       time.Time         `json:"created_at"`
+	// Files maps each generated file (slash-separated, relative to the
+	// project root) to its SHA-256 checksum.
+	Files map[string]string `json:"files"`
+}
+
+// FileState describes how a generated file compares to the manifest.
+type FileState string
+
+const (
+	FileUnchanged FileState = "unchanged"
+	FileModified  FileState = "modified"
+	FileMissing   FileState = "missing"
+)
+
+// FileStatus is the state of one generated file.
+type FileStatus struct {
+	Path  string    `json:"path"`
+	State FileState `json:"state"`
+}
+
+// writeManifest checksums the generated files and saves the manifest.
+func writeManifest(ctx *BuildContext) error {
+	paths := []string{"go.mod", "go.sum"}
+	for rel := range ctx.Files {
+		paths = append(paths, rel)
+	}
+
+	files := map[string]string{}
+	for _, rel := range paths {
+		sum, err := checksumFile(filepath.Join(ctx.Path, filepath.FromSlash(rel)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		files[rel] = sum
+	}
+
+	templateVersion := Version
+	if v, ok := ctx.Template.(extensions.Versioner); ok {
+		templateVersion = v.Version()
+	}
+
+	manifest := &Manifest{
+		Template:        ctx.Template.Name(),
+		TemplateVersion: templateVersion,
+		Params:          manifestParams(ctx),
+		AddOns:          ctx.App.addOns(ctx.Template),
+		EndmiVersion:    Version,
+		GoVersion:       ctx.App.Toolchain.Version,
+		CreatedAt:       time.Now(),
+		Files:           files,
+	}
+
+	if err := saveManifest(ctx.Path, manifest); err != nil {
+		return err
+	}
+	ctx.Report.Manifest = manifest
+	return nil
+}
+
+// manifestParams merges the user parameters with the project name and
+// module path.
+func manifestParams(ctx *BuildContext) map[string]string {
+	params := map[string]string{}
+	for k, v := range ctx.Params {
+		params[k] = v
+	}
+	params["name"] = ctx.Name
+	params["module"] = ctx.ModulePath
+	return params
+}
+
+func saveManifest(projectPath string, manifest *Manifest) error {
+	manifestPath := filepath.Join(projectPath, ManifestFile)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath, data, 0644)
+}
+
+// LoadManifest reads the generation manifest of the project at projectPath.
+func LoadManifest(projectPath string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("'%s' has no %s; %w", projectPath, ManifestFile, ErrNoManifest)
+		}
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// CheckManifest compares every generated file against its recorded checksum.
+// Results are sorted by path.
+func CheckManifest(projectPath string, manifest *Manifest) ([]FileStatus, error) {
+	var statuses []FileStatus
+	for rel, want := range manifest.Files {
+		status := FileStatus{Path: rel, State: FileUnchanged}
+
+		got, err := checksumFile(filepath.Join(projectPath, filepath.FromSlash(rel)))
+		switch {
+		case os.IsNotExist(err):
+			status.State = FileMissing
+		case err != nil:
+			return nil, err
+		case got != want:
+			status.State = FileModified
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+	return statuses, nil
+}
+
+func checksumFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
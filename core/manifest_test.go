@@ -0,0 +1,97 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckManifest(t *testing.T) {
+	generated := map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"main.go":         "package main\n",
+		"internal/app.go": "package app\n",
+	}
+
+	tests := []struct {
+		name   string
+		change func(dir string) error
+		want   []FileStatus
+	}{
+		{
+			name:   "unchanged",
+			change: func(string) error { return nil },
+			want: []FileStatus{
+				{Path: "go.mod", State: FileUnchanged},
+				{Path: "internal/app.go", State: FileUnchanged},
+				{Path: "main.go", State: FileUnchanged},
+			},
+		},
+		{
+			name: "modified",
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+			},
+			want: []FileStatus{
+				{Path: "go.mod", State: FileUnchanged},
+				{Path: "internal/app.go", State: FileUnchanged},
+				{Path: "main.go", State: FileModified},
+			},
+		},
+		{
+			name: "missing",
+			change: func(dir string) error {
+				return os.Remove(filepath.Join(dir, "internal", "app.go"))
+			},
+			want: []FileStatus{
+				{Path: "go.mod", State: FileUnchanged},
+				{Path: "internal/app.go", State: FileMissing},
+				{Path: "main.go", State: FileUnchanged},
+			},
+		},
+		{
+			name: "new files are ignored",
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "extra.go"), []byte("package main\n"), 0644)
+			},
+			want: []FileStatus{
+				{Path: "go.mod", State: FileUnchanged},
+				{Path: "internal/app.go", State: FileUnchanged},
+				{Path: "main.go", State: FileUnchanged},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			manifest := &Manifest{Files: map[string]string{}}
+			for rel, content := range generated {
+				path := filepath.Join(dir, filepath.FromSlash(rel))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				sum, err := checksumFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				manifest.Files[rel] = sum
+			}
+
+			if err := tt.change(dir); err != nil {
+				t.Fatal(err)
+			}
+			got, err := CheckManifest(dir, manifest)
+			if err != nil {
+				t.Fatalf("CheckManifest: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckManifest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
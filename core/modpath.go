@@ -0,0 +1,231 @@
+package core
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// moduleLine matches the module directive of a go.mod file.
+var moduleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+
+// readModulePath returns the module path declared in dir/go.mod.
+func readModulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	m := moduleLine.FindSubmatch(data)
+	if m == nil {
+		return "", fmt.Errorf("%s has no module directive", filepath.Join(dir, "go.mod"))
+	}
+	return strings.Trim(string(m[1]), `"`), nil
+}
+
+// RewriteModulePath renames the module in dir from its current path to
+// modulePath: the go.mod module line and every import of the module's own
+// packages are rewritten. It returns the .go files, relative to dir, that
+// do not parse and so keep their imports of the old path.
+func RewriteModulePath(dir, modulePath string) ([]string, error) {
+	if err := ValidateModulePath(modulePath); err != nil {
+		return nil, err
+	}
+	old, err := readModulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if old == modulePath {
+		return nil, nil
+	}
+
+	goModPath := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+	data = moduleLine.ReplaceAll(data, []byte("module "+modulePath))
+	if err := os.WriteFile(goModPath, data, 0644); err != nil {
+		return nil, err
+	}
+
+	return rewriteImports(dir, old, modulePath)
+}
+
+// rewriteImports changes imports of from, or of packages below it, to the
+// same packages under to in every .go file in dir, leaving vendor alone.
+// Files that do not parse are left for the user to fix and returned,
+// slash-separated and relative to dir.
+func rewriteImports(dir, from, to string) ([]string, error) {
+	var unparsed []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == "vendor" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := rewriteGoFile(src, from, to, "")
+		if err != nil {
+			rel, _ := filepath.Rel(dir, path)
+			unparsed = append(unparsed, filepath.ToSlash(rel))
+			return nil
+		}
+		if string(out) == string(src) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, out, info.Mode().Perm())
+	})
+	return unparsed, err
+}
+
+// warnUnparsed warns that files kept their imports of the old module path
+// because they do not parse, leaving a module rename incomplete.
+func (a App) warnUnparsed(files []string) {
+	if len(files) == 0 {
+		return
+	}
+	a.emit(Event{Kind: EventWarning, Line: fmt.Sprintf(
+		"module renamed, but %s could not be parsed and may still import the old path; fix them by hand",
+		strings.Join(files, ", "))})
+}
+
+// rewriteGoFile moves imports of from, or of packages below it, under to
+// and, if packageName is set, renames the package clause, keeping a _test
+// suffix. The rest of the file is left byte for byte as it was.
+func rewriteGoFile(src []byte, from, to, packageName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	if packageName != "" {
+		name := packageName
+		if strings.HasSuffix(f.Name.Name, "_test") {
+			name += "_test"
+		}
+		edits = append(edits, edit{fset.Position(f.Name.Pos()).Offset, fset.Position(f.Name.End()).Offset, name})
+	}
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || (importPath != from && !strings.HasPrefix(importPath, from+"/")) {
+			continue
+		}
+		edits = append(edits, edit{
+			fset.Position(imp.Path.Pos()).Offset,
+			fset.Position(imp.Path.End()).Offset,
+			strconv.Quote(to + strings.TrimPrefix(importPath, from)),
+		})
+	}
+
+	// Apply back to front so earlier offsets stay valid.
+	out := append([]byte{}, src...)
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out, nil
+}
+
+// requiredModules returns the modules dir/go.mod requires, mapped to their
+// versions.
+func requiredModules(dir string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	mods := make(map[string]string)
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inBlock:
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) == 2 {
+			mods[fields[0]] = fields[1]
+		}
+	}
+	return mods, nil
+}
+
+// modulesFor returns, as module@version, the required modules that provide
+// imports, picking the longest matching module path for each import.
+func modulesFor(imports []string, required map[string]string) []string {
+	seen := make(map[string]bool)
+	var mods []string
+	for _, imp := range imports {
+		best := ""
+		for mod := range required {
+			if (imp == mod || strings.HasPrefix(imp, mod+"/")) && len(mod) > len(best) {
+				best = mod
+			}
+		}
+		if best != "" && !seen[best] {
+			seen[best] = true
+			mods = append(mods, best+"@"+required[best])
+		}
+	}
+	sort.Strings(mods)
+	return mods
+}
+
+// findModule returns the root directory and module path of the module
+// containing dir, which need not exist yet.
+func findModule(dir string) (string, string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			modulePath, err := readModulePath(d)
+			return d, modulePath, err
+		}
+		if filepath.Dir(d) == d {
+			return "", "", fmt.Errorf("no go.mod found in %s or any parent directory", abs)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRewriteGoFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		from, to    string
+		packageName string
+		want        string
+	}{
+		{
+			name: "module root and subpackages",
+			src:  "package main\n\nimport (\n\t\"example.com/old\"\n\t\"example.com/old/internal/db\"\n\t\"fmt\"\n)\n",
+			from: "example.com/old", to: "example.com/new",
+			want: "package main\n\nimport (\n\t\"example.com/new\"\n\t\"example.com/new/internal/db\"\n\t\"fmt\"\n)\n",
+		},
+		{
+			name: "named imports keep their name",
+			src:  "package main\n\nimport db \"example.com/old/db\"\n",
+			from: "example.com/old", to: "new",
+			want: "package main\n\nimport db \"new/db\"\n",
+		},
+		{
+			name: "paths sharing a prefix are left alone",
+			src:  "package main\n\nimport \"example.com/older/pkg\"\n",
+			from: "example.com/old", to: "example.com/new",
+			want: "package main\n\nimport \"example.com/older/pkg\"\n",
+		},
+		{
+			name: "code after the imports is untouched",
+			src:  "package main\n\nimport \"example.com/old/a\"\n\n// example.com/old/a is mentioned here\nvar s = \"example.com/old/a\"\n",
+			from: "example.com/old", to: "example.com/new",
+			want: "package main\n\nimport \"example.com/new/a\"\n\n// example.com/old/a is mentioned here\nvar s = \"example.com/old/a\"\n",
+		},
+		{
+			name: "package clause renamed",
+			src:  "package old\n\nimport \"example.com/old/a\"\n",
+			from: "example.com/old", to: "example.com/new", packageName: "new",
+			want: "package new\n\nimport \"example.com/new/a\"\n",
+		},
+		{
+			name: "external test package keeps its suffix",
+			src:  "package old_test\n",
+			from: "example.com/old", to: "example.com/new", packageName: "new",
+			want: "package new_test\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteGoFile([]byte(tt.src), tt.from, tt.to, tt.packageName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("rewriteGoFile =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := rewriteGoFile([]byte("not go"), "a", "b", ""); err == nil {
+		t.Error("rewriteGoFile accepted a file that does not parse")
+	}
+}
+
+func TestRequiredModules(t *testing.T) {
+	tests := []struct {
+		name  string
+		gomod string
+		want  map[string]string
+	}{
+		{
+			name:  "no requirements",
+			gomod: "module example.com/app\n\ngo 1.22\n",
+			want:  map[string]string{},
+		},
+		{
+			name:  "single line",
+			gomod: "module example.com/app\n\nrequire github.com/google/uuid v1.6.0\n",
+			want:  map[string]string{"github.com/google/uuid": "v1.6.0"},
+		},
+		{
+			name: "blocks with comments",
+			gomod: "module example.com/app\n\ngo 1.22\n\nrequire (\n" +
+				"\tgithub.com/a/b v1.0.0\n" +
+				"\t// a comment\n" +
+				"\tgolang.org/x/text v0.14.0 // indirect\n" +
+				")\n\nrequire github.com/c/d v2.1.0+incompatible\n\n" +
+				"replace github.com/a/b => ../b\n\nexclude (\n\tgithub.com/e/f v1.0.0\n)\n",
+			want: map[string]string{
+				"github.com/a/b":    "v1.0.0",
+				"golang.org/x/text": "v0.14.0",
+				"github.com/c/d":    "v2.1.0+incompatible",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.gomod), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := requiredModules(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredModules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := requiredModules(t.TempDir()); err == nil {
+		t.Error("requiredModules succeeded without a go.mod")
+	}
+}
+
+func TestModulesFor(t *testing.T) {
+	required := map[string]string{
+		"github.com/a/b":       "v1.0.0",
+		"github.com/a/b/v2":    "v2.3.0",
+		"github.com/a/bc":      "v0.1.0",
+		"golang.org/x/text":    "v0.14.0",
+		"github.com/unused/go": "v1.2.3",
+	}
+	tests := []struct {
+		name    string
+		imports []string
+		want    []string
+	}{
+		{"none", nil, nil},
+		{"module root", []string{"github.com/a/b"}, []string{"github.com/a/b@v1.0.0"}},
+		{"subpackage", []string{"golang.org/x/text/cases"}, []string{"golang.org/x/text@v0.14.0"}},
+		{"longest module wins", []string{"github.com/a/b/v2/pkg"}, []string{"github.com/a/b/v2@v2.3.0"}},
+		{"prefix is not a parent", []string{"github.com/a/bc/x"}, []string{"github.com/a/bc@v0.1.0"}},
+		{"deduplicated and sorted", []string{"golang.org/x/text/language", "github.com/a/b/c", "golang.org/x/text/cases"},
+			[]string{"github.com/a/b@v1.0.0", "golang.org/x/text@v0.14.0"}},
+		{"standard library and unknown imports", []string{"fmt", "example.com/other"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modulesFor(tt.imports, required); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("modulesFor(%v) = %v, want %v", tt.imports, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteImportsReportsUnparsedFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":         "package main\n\nimport \"example.com/old/pkg\"\n\nfunc main() { pkg.Run() }\n",
+		"pkg/pkg.go":      "package pkg\n\nfunc Run() {}\n",
+		"pkg/broken.go":   "package pkg\n\nimport \"example.com/old/util\n",
+		"vendor/x/x.go":   "this is not go\n",
+		".hidden/skip.go": "nor is this\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	unparsed, err := rewriteImports(dir, "example.com/old", "example.com/new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"pkg/broken.go"}; !reflect.DeepEqual(unparsed, want) {
+		t.Errorf("unparsed = %v, want %v", unparsed, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "package main\n\nimport \"example.com/new/pkg\"\n\nfunc main() { pkg.Run() }\n"; string(data) != want {
+		t.Errorf("main.go =\n%s\nwant\n%s", data, want)
+	}
+}
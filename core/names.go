@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NameError reports an unusable project name or module path. Suggestion,
+// when set, is a valid alternative derived from Name.
+type NameError struct {
+	Name       string
+	Reason     string
+	Suggestion string
+}
+
+func (e *NameError) Error() string {
+	msg := fmt.Sprintf("invalid name %q: %s", e.Name, e.Reason)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(" (try %q)", e.Suggestion)
+	}
+	return msg
+}
+
+// reservedNames can't be used as a project name: they clash with go command
+// patterns and packages (std, cmd, all, main, test) or are device names on
+// Windows.
+var reservedNames = map[string]bool{
+	"main": true, "std": true, "cmd": true, "all": true, "test": true, "tool": true,
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// maxNameLength keeps directory names well under filesystem limits.
+const maxNameLength = 64
+
+// ValidateProjectName checks that name is safe both as a directory name on
+// every platform and as a Go module path: lowercase ASCII letters, digits,
+// '-', '_' and '.', starting with a letter or digit, and not reserved.
+func ValidateProjectName(name string) error {
+	reason := projectNameProblem(name)
+	if reason == "" {
+		return nil
+	}
+	return &NameError{Name: name, Reason: reason, Suggestion: SuggestProjectName(name)}
+}
+
+func projectNameProblem(name string) string {
+	switch {
+	case name == "":
+		return "name is empty"
+	case len(name) > maxNameLength:
+		return fmt.Sprintf("name is longer than %d characters", maxNameLength)
+	case strings.ContainsAny(name, `/\`):
+		return "name must not contain path separators"
+	case strings.ContainsAny(name, " \t"):
+		return "name must not contain spaces"
+	}
+
+	for _, r := range name {
+		switch {
+		case r > 0x7f:
+			return "name must be ASCII"
+		case r >= 'A' && r <= 'Z':
+			return "name must be lowercase"
+		case !isNameChar(r):
+			return fmt.Sprintf("name must not contain %q", r)
+		}
+	}
+
+	first := name[0]
+	if !(first >= 'a' && first <= 'z' || first >= '0' && first <= '9') {
+		return "name must start with a letter or digit"
+	}
+	if strings.HasSuffix(name, ".") {
+		return "name must not end with a dot"
+	}
+
+	base := name
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if reservedNames[base] {
+		return fmt.Sprintf("%q is reserved", base)
+	}
+
+	return ""
+}
+
+func isNameChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'
+}
+
+// ValidateModulePath checks path against the go command's module path rules:
+// slash-separated, non-empty elements of ASCII letters, digits and "-._~",
+// no element starting or ending with a dot, and a first element that does
+// not start with a dash.
+func ValidateModulePath(path string) error {
+	fail := func(reason string) error {
+		return &NameError{Name: path, Reason: reason, Suggestion: suggestModulePath(path)}
+	}
+
+	if path == "" {
+		return fail("module path is empty")
+	}
+	if strings.HasPrefix(path, "-") {
+		return fail("module path must not start with a dash")
+	}
+
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" {
+			return fail("module path has an empty element")
+		}
+		if strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return fail(fmt.Sprintf("element %q must not start or end with a dot", elem))
+		}
+		for _, r := range elem {
+			if !isModulePathChar(r) {
+				return fail(fmt.Sprintf("element %q must not contain %q", elem, r))
+			}
+		}
+	}
+
+	first := strings.SplitN(path, "/", 2)[0]
+	if reservedNames[strings.ToLower(first)] {
+		return fail(fmt.Sprintf("%q is reserved", first))
+	}
+
+	return nil
+}
+
+func isModulePathChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// asciiFold maps common accented Latin letters to their plain form.
+var asciiFold = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// SuggestProjectName turns an arbitrary string into a valid project name,
+// e.g. "My API" becomes "my-api".
+func SuggestProjectName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range asciiFold.Replace(strings.ToLower(name)) {
+		if isNameChar(r) && r != '-' && r != '.' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-_")
+	if len(slug) > maxNameLength {
+		slug = strings.Trim(slug[:maxNameLength], "-_")
+	}
+	if slug == "" {
+		return "my-project"
+	}
+	if projectNameProblem(slug) != "" {
+		slug += "-app"
+	}
+	return slug
+}
+
+func suggestModulePath(path string) string {
+	var elems []string
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" {
+			continue
+		}
+		elems = append(elems, SuggestProjectName(elem))
+	}
+	if len(elems) == 0 {
+		return "my-project"
+	}
+	return strings.Join(elems, "/")
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateProjectName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"my-app", false},
+		{"app_2", false},
+		{"1st", false},
+		{"api.v2", false},
+		{strings.Repeat("a", maxNameLength), false},
+		{"", true},
+		{strings.Repeat("a", maxNameLength+1), true},
+		{"My-App", true},
+		{"my app", true},
+		{"my/app", true},
+		{`my\app`, true},
+		{"café", true},
+		{"app!", true},
+		{"-app", true},
+		{"_app", true},
+		{".app", true},
+		{"app.", true},
+		{"main", true},
+		{"std", true},
+		{"con.txt", true},
+		{"lpt1", true},
+	}
+	for _, tt := range tests {
+		err := ValidateProjectName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateProjectName(%q) = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		var nameErr *NameError
+		if !errors.As(err, &nameErr) {
+			t.Errorf("ValidateProjectName(%q) returned %T, want *NameError", tt.name, err)
+			continue
+		}
+		if err := ValidateProjectName(nameErr.Suggestion); err != nil {
+			t.Errorf("ValidateProjectName(%q) suggested %q, which is invalid: %v", tt.name, nameErr.Suggestion, err)
+		}
+	}
+}
+
+func TestSuggestProjectName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"my-api", "my-api"},
+		{"My API", "my-api"},
+		{"  Hello,   World!  ", "hello-world"},
+		{"Café Ünïcode", "cafe-unicode"},
+		{"api.v2", "api-v2"},
+		{"_private_", "private"},
+		{"snake_case", "snake_case"},
+		{"main", "main-app"},
+		{"CON", "con-app"},
+		{"!!!", "my-project"},
+		{"", "my-project"},
+		{strings.Repeat("a", maxNameLength+10), strings.Repeat("a", maxNameLength)},
+	}
+	for _, tt := range tests {
+		if got := SuggestProjectName(tt.in); got != tt.want {
+			t.Errorf("SuggestProjectName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateModulePath(t *testing.T) {
+	tests := []struct {
+		path           string
+		wantErr        bool
+		wantSuggestion string
+	}{
+		{"github.com/user/my-app", false, ""},
+		{"example.com/App_v2~x", false, ""},
+		{"myapp", false, ""},
+		{"", true, "my-project"},
+		{"-app", true, "app"},
+		{"github.com//app", true, "github-com/app"},
+		{"github.com/user/.app", true, "github-com/user/app"},
+		{"github.com/user/app.", true, "github-com/user/app"},
+		{"github.com/my app", true, "github-com/my-app"},
+		{"main", true, "main-app"},
+		{"Std/pkg", true, "std-app/pkg"},
+	}
+	for _, tt := range tests {
+		err := ValidateModulePath(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateModulePath(%q) = %v, want error %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		var nameErr *NameError
+		if !errors.As(err, &nameErr) {
+			t.Errorf("ValidateModulePath(%q) returned %T, want *NameError", tt.path, err)
+			continue
+		}
+		if nameErr.Suggestion != tt.wantSuggestion {
+			t.Errorf("ValidateModulePath(%q) suggested %q, want %q", tt.path, nameErr.Suggestion, tt.wantSuggestion)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dlcuy22/endmi/extensions"
+)
+
+// BuildContext carries the state of one project creation through the
+// pipeline steps.
+type BuildContext struct {
+	App      App
+	Template extensions.Template
+	// Name is the project name, ModulePath the path given to `go mod init`.
+	Name       string
+	ModulePath string
+	// Path is the project directory.
+	Path string
+	// Params are the user-supplied template parameters.
+	Params map[string]string
+	// Files holds the rendered files keyed by slash-separated path relative
+	// to Path. Steps before PhaseWriteFiles may change it.
+	Files  map[string]string
+	Report *Report
+}
+
+// Step is a named stage of project creation. Each step is reported as a
+// phase in the event stream and the report timings.
+type Step struct {
+	Name Phase
+	Run  func(ctx *BuildContext) error
+	// When, if set, decides whether the step applies; skipped steps emit no
+	// events.
+	When func(ctx *BuildContext) bool
+	// Items, if set, is the number of items the step works through, reported
+	// as the phase total.
+	Items func(ctx *BuildContext) int
+	// Concurrent steps run at the same time as adjacent concurrent steps.
+	Concurrent bool
+	// Optional steps report their failure but do not stop the pipeline.
+	Optional bool
+}
+
+// StepError reports which step of the pipeline failed.
+type StepError struct {
+	Step Phase
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline is an ordered list of creation steps. Callers can insert, replace
+// or skip steps by name before it runs.
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline returns a pipeline running steps in order.
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// Steps returns the steps in run order.
+func (p *Pipeline) Steps() []Step {
+	return append([]Step(nil), p.steps...)
+}
+
+// Append adds a step at the end.
+func (p *Pipeline) Append(step Step) {
+	p.steps = append(p.steps, step)
+}
+
+// InsertBefore adds step before the step called name.
+func (p *Pipeline) InsertBefore(name Phase, step Step) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps = append(p.steps[:i], append([]Step{step}, p.steps[i:]...)...)
+	return nil
+}
+
+// InsertAfter adds step after the step called name.
+func (p *Pipeline) InsertAfter(name Phase, step Step) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps = append(p.steps[:i+1], append([]Step{step}, p.steps[i+1:]...)...)
+	return nil
+}
+
+// Replace swaps the step called name for step.
+func (p *Pipeline) Replace(name Phase, step Step) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps[i] = step
+	return nil
+}
+
+// Skip removes the step called name.
+func (p *Pipeline) Skip(name Phase) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	p.steps = append(p.steps[:i], p.steps[i+1:]...)
+	return nil
+}
+
+func (p *Pipeline) index(name Phase) (int, error) {
+	for i, step := range p.steps {
+		if step.Name == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("pipeline has no step '%s'", name)
+}
+
+// Run executes the steps in order, running adjacent concurrent steps
+// together. It stops at the first failing step that is not optional and
+// returns its error as a *StepError.
+func (p *Pipeline) Run(ctx *BuildContext) error {
+	for i := 0; i < len(p.steps); {
+		group := []Step{p.steps[i]}
+		i++
+		for group[0].Concurrent && i < len(p.steps) && p.steps[i].Concurrent {
+			group = append(group, p.steps[i])
+			i++
+		}
+
+		if err := p.runGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) runGroup(ctx *BuildContext, group []Step) error {
+	errs := make([]error, len(group))
+
+	var wg sync.WaitGroup
+	for i, step := range group {
+		if step.When != nil && !step.When(ctx) {
+			continue
+		}
+
+		run := func() {
+			items := 0
+			if step.Items != nil {
+				items = step.Items(ctx)
+			}
+			err := ctx.App.runPhase(ctx.Report, step.Name, items, func() error {
+				return step.Run(ctx)
+			})
+			if err != nil && !step.Optional {
+				errs[i] = &StepError{Step: step.Name, Err: err}
+			}
+		}
+
+		if len(group) == 1 {
+			run()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPipelineEdits(t *testing.T) {
+	step := func(name Phase) Step { return Step{Name: name} }
+
+	tests := []struct {
+		name    string
+		edit    func(p *Pipeline) error
+		want    []Phase
+		wantErr bool
+	}{
+		{
+			name: "insert before first",
+			edit: func(p *Pipeline) error { return p.InsertBefore("a", step("x")) },
+			want: []Phase{"x", "a", "b", "c"},
+		},
+		{
+			name: "insert before middle",
+			edit: func(p *Pipeline) error { return p.InsertBefore("b", step("x")) },
+			want: []Phase{"a", "x", "b", "c"},
+		},
+		{
+			name:    "insert before unknown",
+			edit:    func(p *Pipeline) error { return p.InsertBefore("z", step("x")) },
+			want:    []Phase{"a", "b", "c"},
+			wantErr: true,
+		},
+		{
+			name: "insert after last",
+			edit: func(p *Pipeline) error { return p.InsertAfter("c", step("x")) },
+			want: []Phase{"a", "b", "c", "x"},
+		},
+		{
+			name: "replace",
+			edit: func(p *Pipeline) error { return p.Replace("b", step("x")) },
+			want: []Phase{"a", "x", "c"},
+		},
+		{
+			name:    "replace unknown",
+			edit:    func(p *Pipeline) error { return p.Replace("z", step("x")) },
+			want:    []Phase{"a", "b", "c"},
+			wantErr: true,
+		},
+		{
+			name: "skip first",
+			edit: func(p *Pipeline) error { return p.Skip("a") },
+			want: []Phase{"b", "c"},
+		},
+		{
+			name: "skip last",
+			edit: func(p *Pipeline) error { return p.Skip("c") },
+			want: []Phase{"a", "b"},
+		},
+		{
+			name:    "skip unknown",
+			edit:    func(p *Pipeline) error { return p.Skip("z") },
+			want:    []Phase{"a", "b", "c"},
+			wantErr: true,
+		},
+		{
+			name: "skip then insert before the next step",
+			edit: func(p *Pipeline) error {
+				if err := p.Skip("b"); err != nil {
+					return err
+				}
+				return p.InsertBefore("c", step("x"))
+			},
+			want: []Phase{"a", "x", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPipeline(step("a"), step("b"), step("c"))
+			before := p.Steps()
+
+			err := tt.edit(p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			var got []Phase
+			for _, s := range p.Steps() {
+				got = append(got, s.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("steps = %v, want %v", got, tt.want)
+			}
+			if len(before) != 3 || before[0].Name != "a" || before[1].Name != "b" || before[2].Name != "c" {
+				t.Errorf("editing the pipeline changed a slice returned by Steps: %v", before)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package core
+
+import "os/exec"
+
+// terminalSendsInterrupt reports whether a Ctrl-C reaches child processes
+// without endmi's help, which on Windows consoles it always does.
+func terminalSendsInterrupt() bool {
+	return true
+}
+
+// killGroupOnCancel leaves cmd as it is: cancelling it kills only cmd's own
+// process.
+func killGroupOnCancel(cmd *exec.Cmd) {}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package core
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalSendsInterrupt reports whether endmi's process group is the
+// foreground group of its controlling terminal. A Ctrl-C there already
+// interrupts every process in the group, children included.
+func terminalSendsInterrupt() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	pgrp, err := unix.IoctlGetInt(int(tty.Fd()), unix.TIOCGPGRP)
+	return err == nil && pgrp == unix.Getpgrp()
+}
+
+// killGroupOnCancel starts cmd in a process group of its own and makes
+// cancelling its context kill the whole group, so processes cmd started,
+// like the test binaries of `go test`, go down with it.
+func killGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return unix.Kill(-cmd.Process.Pid, unix.SIGKILL)
+	}
+}
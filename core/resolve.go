@@ -0,0 +1,134 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AmbiguousNameError is returned by ResolveTempProject when a name matches
+// more than one temp project.
+type AmbiguousNameError struct {
+	Name string
+	// Candidates are the matching projects, most recently used first.
+	Candidates []TempProjectMetadata
+}
+
+func (e *AmbiguousNameError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = c.Name
+	}
+	return fmt.Sprintf("'%s' matches %d temp projects: %s (use a longer name)",
+		e.Name, len(e.Candidates), strings.Join(names, ", "))
+}
+
+// ResolveTempProject finds a temp project or scratch snippet from a
+// reference, trying in turn:
+//
+//   - @last: the most recently used project
+//   - @1, @2, ...: the most recently used project, the one before it, ...
+//   - the exact name
+//   - a unique name prefix, e.g. "temp_1712" for "temp_1712345678"
+//   - a unique fuzzy match, whose characters appear in the name in order,
+//     e.g. "t178" for "temp_1712345678"
+//
+// A prefix or fuzzy match of several projects returns an
+// *AmbiguousNameError listing them. An empty name is an error, so a missing
+// argument never picks a project by accident.
+func (tcm *TempCodeManager) ResolveTempProject(name string) (TempProjectMetadata, error) {
+	return tcm.resolveTempProject(name, true)
+}
+
+// ResolveTempProjectStrict is ResolveTempProject without fuzzy matching:
+// only @last, @N, exact names and unique prefixes resolve. It is used by
+// commands that delete, move or overwrite a project, where a loose match
+// could hit the wrong one.
+func (tcm *TempCodeManager) ResolveTempProjectStrict(name string) (TempProjectMetadata, error) {
+	return tcm.resolveTempProject(name, false)
+}
+
+func (tcm *TempCodeManager) resolveTempProject(name string, fuzzy bool) (TempProjectMetadata, error) {
+	if name == "" {
+		return TempProjectMetadata{}, errors.New("no temp project name given")
+	}
+	if name == "@last" {
+		return tcm.MostRecentTempProject()
+	}
+	if strings.HasPrefix(name, "@") {
+		n, err := strconv.Atoi(name[1:])
+		if err != nil || n < 1 {
+			return TempProjectMetadata{}, fmt.Errorf("invalid reference '%s' (use @last, @1, @2, ...)", name)
+		}
+		return tcm.recentTempProject(n)
+	}
+
+	if project, err := tcm.lookupTempProject(name); err == nil {
+		return project, nil
+	}
+
+	projects, err := tcm.RecentTempProjects()
+	if err != nil {
+		return TempProjectMetadata{}, err
+	}
+	matchers := []func(string, string) bool{strings.HasPrefix}
+	if fuzzy {
+		matchers = append(matchers, fuzzyMatch)
+	}
+	for _, match := range matchers {
+		var candidates []TempProjectMetadata
+		for _, p := range projects {
+			if match(strings.ToLower(p.Name), strings.ToLower(name)) {
+				candidates = append(candidates, p)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return TempProjectMetadata{}, &AmbiguousNameError{Name: name, Candidates: candidates}
+		}
+	}
+	if !fuzzy {
+		// Say why a name that works elsewhere does not work here.
+		for _, p := range projects {
+			if fuzzyMatch(strings.ToLower(p.Name), strings.ToLower(name)) {
+				return TempProjectMetadata{}, fmt.Errorf("%w: '%s' (it fuzzy-matches '%s', but this command needs an exact name, a prefix or @N)",
+					ErrProjectNotFound, name, p.Name)
+			}
+		}
+	}
+	return TempProjectMetadata{}, fmt.Errorf("%w: '%s'", ErrProjectNotFound, name)
+}
+
+// recentTempProject returns the nth most recently used temp project,
+// counting from 1.
+func (tcm *TempCodeManager) recentTempProject(n int) (TempProjectMetadata, error) {
+	projects, err := tcm.RecentTempProjects()
+	if err != nil {
+		return TempProjectMetadata{}, err
+	}
+	if len(projects) == 0 {
+		return TempProjectMetadata{}, fmt.Errorf("%w: no temporary projects found", ErrProjectNotFound)
+	}
+	if n > len(projects) {
+		return TempProjectMetadata{}, fmt.Errorf("%w: @%d, there are only %d temporary projects", ErrProjectNotFound, n, len(projects))
+	}
+	return projects[n-1], nil
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in
+// order, not necessarily next to each other.
+func fuzzyMatch(s, pattern string) bool {
+	for _, r := range pattern {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
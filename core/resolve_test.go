@@ -0,0 +1,178 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// newTestWorkspace returns a manager whose temp dir holds these projects,
+// listed most recently used first:
+//
+//	temp_1799999999, api-server, temp_1712345678, apple, snip_1 (scratch), api
+func newTestWorkspace(t *testing.T) *TempCodeManager {
+	t.Helper()
+	tcm := &TempCodeManager{App: &App{}, Config: &utils.Config{TempDir: t.TempDir()}}
+	now := time.Now()
+
+	projects := []struct {
+		name     string
+		created  time.Duration
+		lastUsed time.Duration
+		scratch  bool
+	}{
+		{name: "temp_1799999999", created: 6 * time.Hour, lastUsed: 30 * time.Minute},
+		{name: "api-server", created: time.Hour},
+		{name: "temp_1712345678", created: 2 * time.Hour},
+		{name: "apple", created: 3 * time.Hour},
+		{name: "snip_1", created: 4 * time.Hour, scratch: true},
+		{name: "api", created: 5 * time.Hour},
+	}
+	for _, p := range projects {
+		dir := filepath.Join(tcm.Config.TempDir, p.name)
+		if p.scratch {
+			dir = filepath.Join(tcm.Config.TempDir, ScratchDir, p.name)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		meta := TempProjectMetadata{
+			Name:      p.name,
+			CreatedAt: now.Add(-p.created),
+			Template:  "basic",
+			Path:      dir,
+			Scratch:   p.scratch,
+		}
+		if p.lastUsed > 0 {
+			meta.LastUsedAt = now.Add(-p.lastUsed)
+		}
+		if err := tcm.saveMetadata(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tcm
+}
+
+func TestResolveTempProject(t *testing.T) {
+	tcm := newTestWorkspace(t)
+
+	tests := []struct {
+		name          string
+		ref           string
+		want          string
+		wantAmbiguous []string
+		wantNotFound  bool
+		wantErr       bool
+		// strict is what ResolveTempProjectStrict should give, when it
+		// differs.
+		strictNotFound bool
+	}{
+		{name: "empty", ref: "", wantErr: true},
+		{name: "last", ref: "@last", want: "temp_1799999999"},
+		{name: "first", ref: "@1", want: "temp_1799999999"},
+		{name: "second", ref: "@2", want: "api-server"},
+		{name: "scratch by recency", ref: "@5", want: "snip_1"},
+		{name: "oldest", ref: "@6", want: "api"},
+		{name: "past the end", ref: "@7", wantNotFound: true},
+		{name: "zero", ref: "@0", wantErr: true},
+		{name: "not a number", ref: "@x", wantErr: true},
+		{name: "exact", ref: "apple", want: "apple"},
+		{name: "exact scratch", ref: "snip_1", want: "snip_1"},
+		{name: "exact beats prefix", ref: "api", want: "api"},
+		{name: "prefix", ref: "temp_1712", want: "temp_1712345678"},
+		{name: "prefix ignores case", ref: "API-", want: "api-server"},
+		{name: "ambiguous prefix", ref: "temp_", wantAmbiguous: []string{"temp_1799999999", "temp_1712345678"}},
+		{name: "ambiguous prefix in recency order", ref: "ap", wantAmbiguous: []string{"api-server", "apple", "api"}},
+		{name: "fuzzy", ref: "t178", want: "temp_1712345678", strictNotFound: true},
+		{name: "fuzzy across separators", ref: "apsrv", want: "api-server", strictNotFound: true},
+		{name: "ambiguous fuzzy", ref: "e1", wantAmbiguous: []string{"temp_1799999999", "temp_1712345678"}, strictNotFound: true},
+		{name: "no match", ref: "zzz", wantNotFound: true},
+		{name: "path outside the workspace", ref: "../x", wantNotFound: true},
+	}
+
+	check := func(t *testing.T, got TempProjectMetadata, err error, want string, wantAmbiguous []string, wantNotFound, wantErr bool) {
+		t.Helper()
+		var ambiguous *AmbiguousNameError
+		switch {
+		case wantAmbiguous != nil:
+			if !errors.As(err, &ambiguous) {
+				t.Fatalf("err = %v, want *AmbiguousNameError", err)
+			}
+			var names []string
+			for _, c := range ambiguous.Candidates {
+				names = append(names, c.Name)
+			}
+			if !reflect.DeepEqual(names, wantAmbiguous) {
+				t.Errorf("candidates = %v, want %v", names, wantAmbiguous)
+			}
+		case wantNotFound:
+			if !errors.Is(err, ErrProjectNotFound) {
+				t.Errorf("err = %v, want ErrProjectNotFound", err)
+			}
+		case wantErr:
+			if err == nil {
+				t.Errorf("resolved %q, want an error", got.Name)
+			}
+		default:
+			if err != nil {
+				t.Fatalf("err = %v, want %q", err, want)
+			}
+			if got.Name != want {
+				t.Errorf("resolved %q, want %q", got.Name, want)
+			}
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tcm.ResolveTempProject(tt.ref)
+			check(t, got, err, tt.want, tt.wantAmbiguous, tt.wantNotFound, tt.wantErr)
+
+			got, err = tcm.ResolveTempProjectStrict(tt.ref)
+			if tt.strictNotFound {
+				check(t, got, err, "", nil, true, false)
+			} else {
+				check(t, got, err, tt.want, tt.wantAmbiguous, tt.wantNotFound, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveTempProjectEmptyWorkspace(t *testing.T) {
+	tcm := &TempCodeManager{App: &App{}, Config: &utils.Config{TempDir: t.TempDir()}}
+	for _, ref := range []string{"@last", "@1", "temp", "t1"} {
+		if _, err := tcm.ResolveTempProject(ref); !errors.Is(err, ErrProjectNotFound) {
+			t.Errorf("ResolveTempProject(%q) = %v, want ErrProjectNotFound", ref, err)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"temp_1712345678", "", true},
+		{"temp_1712345678", "temp_1712345678", true},
+		{"temp_1712345678", "t178", true},
+		{"temp_1712345678", "tmp", true},
+		{"temp_1712345678", "t871", false},
+		{"temp_1712345678", "temp_17123456789", false},
+		{"api-server", "asr", true},
+		{"api-server", "ss", false},
+		{"aab", "ab", true},
+		{"héllo", "hl", true},
+		{"héllo", "éo", true},
+		{"", "a", false},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.s, tt.pattern); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+// ErrBuildFailed is returned by RunTempProject when the project does not
+// compile. The compiler output has already been written to Stderr.
+var ErrBuildFailed = errors.New("build failed")
+
+// RunOptions wires a temp project's program to the caller's terminal.
+type RunOptions struct {
+	// Args are passed to the program.
+	Args   []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// mapBuildOutput, if set, rewrites compiler output before it is
+	// written to Stderr.
+	mapBuildOutput func([]byte) []byte
+}
+
+// RunTempProject builds and runs a temp project's main package, like
+// `go run .`, and returns the program's exit code. An empty name runs the
+// most recently used project.
+//
+// Unlike `go run`, the program is started directly rather than as a
+// grandchild, so its exit code is preserved and SIGINT/SIGTERM received by
+// endmi are forwarded to it, except a SIGINT the terminal already sent it.
+func (tcm *TempCodeManager) RunTempProject(name string, opts RunOptions) (int, error) {
+	if name == "" {
+		name = "@last"
+	}
+	project, err := tcm.ResolveTempProject(name)
+	if err != nil {
+		return -1, err
+	}
+	if err := tcm.touch(project); err != nil {
+		tcm.App.emit(Event{Kind: EventWarning, Line: fmt.Sprintf("failed to record last use: %v", err)})
+	}
+
+	return tcm.App.runProgram(project.Path, opts)
+}
+
+// runProgram compiles the main package in dir into a throwaway binary and
+// runs it with opts.
+func (a App) runProgram(dir string, opts RunOptions) (int, error) {
+	a, err := a.withDefaultToolchain()
+	if err != nil {
+		return -1, err
+	}
+
+	binDir, err := os.MkdirTemp("", "endmi-run-")
+	if err != nil {
+		return -1, err
+	}
+	defer os.RemoveAll(binDir)
+
+	buildStderr := opts.Stderr
+	var buildOutput bytes.Buffer
+	if opts.mapBuildOutput != nil && opts.Stderr != nil {
+		buildStderr = &buildOutput
+	}
+	bin, code, err := a.buildProgram(dir, binDir, opts.Stdout, buildStderr)
+	if buildOutput.Len() > 0 {
+		opts.Stderr.Write(opts.mapBuildOutput(buildOutput.Bytes()))
+	}
+	if err != nil {
+		return code, err
+	}
+
+	cmd := exec.Command(bin, opts.Args...)
+	cmd.Dir = dir
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	// Take over SIGINT/SIGTERM so endmi outlives the program and can report
+	// its exit code; the program receives them instead. A Ctrl-C typed in
+	// the terminal reaches the program directly, so SIGINT is only passed
+	// on when it did not come from there; programs that shut down on the
+	// first SIGINT and quit on the second must not see it twice.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	forwardInterrupt := !terminalSendsInterrupt()
+
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == os.Interrupt && !forwardInterrupt {
+					continue
+				}
+				cmd.Process.Signal(sig) // Ignore errors, e.g. on Windows
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return exitCode(cmd.Wait())
+}
+
+// withDefaultToolchain returns a with the default toolchain selected if
+// none was chosen.
+func (a App) withDefaultToolchain() (App, error) {
+	if a.Toolchain != nil {
+		return a, nil
+	}
+	tc, err := FindToolchain("")
+	if err != nil {
+		return a, err
+	}
+	a.Toolchain = tc
+	return a, nil
+}
+
+// buildProgram compiles the main package in dir into binDir, writing
+// compiler output to stdout and stderr. It returns the binary's path, or
+// ErrBuildFailed with the compiler's exit code.
+func (a App) buildProgram(dir, binDir string, stdout, stderr io.Writer) (string, int, error) {
+	bin := filepath.Join(binDir, filepath.Base(dir))
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+
+	build := a.command(dir, "go", "build", "-o", bin, ".")
+	build.Stdout = stdout
+	build.Stderr = stderr
+	if code, err := exitCode(build.Run()); err != nil || code != 0 {
+		if err == nil {
+			err = ErrBuildFailed
+		}
+		return "", code, err
+	}
+	return bin, 0, nil
+}
+
+// exitCode converts the result of running a command into its exit code.
+// Only failures to run the command at all are returned as errors.
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return -1, err
+	}
+	// Report death by signal the way shells do.
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), nil
+	}
+	return exitErr.ExitCode(), nil
+}
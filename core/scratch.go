@@ -0,0 +1,195 @@
+package core
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScratchDir is the shared scratch module inside the temp dir. Snippets
+// created in scratch mode are packages of this one module, so they skip
+// `go mod init` and share its dependencies.
+const ScratchDir = ".scratch"
+
+// scratchModule is the scratch module's path; a snippet's package is
+// scratchModule/<name>.
+const scratchModule = "scratch"
+
+// ScratchPath returns the scratch module's directory.
+func (tcm *TempCodeManager) ScratchPath() (string, error) {
+	tempDir, err := tcm.GetTempDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(tempDir, ScratchDir), nil
+}
+
+// CreateScratchSnippet adds a snippet as a main package of the scratch
+// module, creating the module on first use. Unless the snippet imports
+// modules outside the standard library, this only writes files.
+func (tcm *TempCodeManager) CreateScratchSnippet(name, source string, opts TempOptions) (*Report, error) {
+	start := time.Now()
+	if err := ValidateProjectName(name); err != nil {
+		return nil, err
+	}
+	if _, err := tcm.lookupTempProject(name); err == nil {
+		return nil, fmt.Errorf("%w: temp project '%s'", ErrProjectExists, name)
+	}
+	if err := tcm.checkQuota(); err != nil {
+		return nil, err
+	}
+
+	scratchPath, err := tcm.ScratchPath()
+	if err != nil {
+		return nil, err
+	}
+	app, err := tcm.App.withDefaultToolchain()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(scratchPath, name)
+	ctx := &BuildContext{
+		App:        app,
+		Template:   snippetTemplate{source: source},
+		Name:       name,
+		ModulePath: scratchModule + "/" + name,
+		Path:       path,
+		Files:      map[string]string{"main.go": source},
+		Report:     &Report{Path: path},
+	}
+
+	p := NewPipeline(
+		Step{
+			Name: PhaseModInit,
+			Run:  initScratchModule,
+			When: func(ctx *BuildContext) bool {
+				_, err := os.Stat(filepath.Join(filepath.Dir(ctx.Path), "go.mod"))
+				return err != nil
+			},
+		},
+		Step{Name: PhaseMkdir, Run: prepareDir},
+		Step{
+			Name:  PhaseWriteFiles,
+			Run:   writeFiles,
+			Items: func(ctx *BuildContext) int { return len(ctx.Files) },
+		},
+		Step{
+			Name: PhaseTidy,
+			Run: func(ctx *BuildContext) error {
+				return ctx.App.runCommand(PhaseTidy, filepath.Dir(ctx.Path), "go", "mod", "tidy")
+			},
+			When: func(ctx *BuildContext) bool { return importsModules(ctx.Files) },
+		},
+		Step{
+			Name: PhaseTempMetadata,
+			Run: func(ctx *BuildContext) error {
+				return tcm.saveMetadata(ctx.Path, TempProjectMetadata{
+					Name:      ctx.Name,
+					CreatedAt: time.Now(),
+					Template:  ctx.Template.Name(),
+					Path:      ctx.Path,
+					TTL:       opts.TTL,
+					Tags:      opts.Tags,
+					Note:      strings.TrimSpace(opts.Note),
+					Scratch:   true,
+				})
+			},
+			Optional: true,
+		},
+	)
+	if err := p.Run(ctx); err != nil {
+		return ctx.Report, err
+	}
+
+	ctx.Report.Total = time.Since(start)
+	return ctx.Report, nil
+}
+
+func initScratchModule(ctx *BuildContext) error {
+	dir := filepath.Dir(ctx.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create scratch module: %w", err)
+	}
+	return ctx.App.runCommand(PhaseModInit, dir, "go", "mod", "init", scratchModule)
+}
+
+// importsModules reports whether any .go file imports a package outside the
+// standard library.
+func importsModules(files map[string]string) bool {
+	fset := token.NewFileSet()
+	for name, src := range files {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err == nil && strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// promoteScratchSnippet extracts a scratch snippet into a standalone module
+// at targetPath, named modulePath (the snippet's name if empty) and
+// requiring what the scratch module requires, pruned by `go mod tidy`.
+func (tcm *TempCodeManager) promoteScratchSnippet(project TempProjectMetadata, targetPath, modulePath string) error {
+	if modulePath == "" {
+		modulePath = project.Name
+	}
+	if err := ValidateModulePath(modulePath); err != nil {
+		return err
+	}
+
+	scratchPath := filepath.Dir(project.Path)
+	goMod, err := os.ReadFile(filepath.Join(scratchPath, "go.mod"))
+	if err != nil {
+		return fmt.Errorf("failed to read scratch module: %w", err)
+	}
+
+	skipMeta := func(rel string) bool { return rel == ".endmi_meta.json" }
+	if err := copyTree(project.Path, targetPath, skipMeta); err != nil {
+		os.RemoveAll(targetPath)
+		return fmt.Errorf("failed to copy snippet: %w", err)
+	}
+	if err := verifyTree(project.Path, targetPath, skipMeta); err != nil {
+		os.RemoveAll(targetPath)
+		return fmt.Errorf("copied snippet differs from the original: %w", err)
+	}
+
+	goMod = moduleLine.ReplaceAll(goMod, []byte("module "+modulePath))
+	if err := os.WriteFile(filepath.Join(targetPath, "go.mod"), goMod, 0644); err != nil {
+		return err
+	}
+	if goSum, err := os.ReadFile(filepath.Join(scratchPath, "go.sum")); err == nil {
+		if err := os.WriteFile(filepath.Join(targetPath, "go.sum"), goSum, 0644); err != nil {
+			return err
+		}
+	}
+	unparsed, err := rewriteImports(targetPath, scratchModule+"/"+project.Name, modulePath)
+	if err != nil {
+		return err
+	}
+	tcm.App.warnUnparsed(unparsed)
+
+	app, err := tcm.App.withDefaultToolchain()
+	if err != nil {
+		return err
+	}
+	if err := app.runCommand(PhaseTidy, targetPath, "go", "mod", "tidy"); err != nil {
+		return fmt.Errorf("promoted, but go mod tidy failed: %w", err)
+	}
+
+	return tcm.removeProject(project)
+}
@@ -0,0 +1,496 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotsDir holds temp project snapshots inside the temp dir: file
+// contents under objects/, stored once per SHA-256, and one JSON file per
+// snapshot under projects/<name>/.
+const SnapshotsDir = ".snapshots"
+
+// Snapshot is a point-in-time record of a temp project's files.
+type Snapshot struct {
+	// ID numbers the project's snapshots from 1.
+	ID        string          `json:"id"`
+	Project   string          `json:"project"`
+	Message   string          `json:"message,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Files     []SnapshotEntry `json:"files"`
+}
+
+// SnapshotEntry is one file, directory or symlink of a snapshot.
+type SnapshotEntry struct {
+	// Path is slash-separated and relative to the project.
+	Path string      `json:"path"`
+	Mode fs.FileMode `json:"mode"`
+	Size int64       `json:"size,omitempty"`
+	// SHA256 names the file's object; Link is a symlink's target.
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"`
+}
+
+// Size returns the total size of the snapshot's files.
+func (s *Snapshot) Size() int64 {
+	var n int64
+	for _, f := range s.Files {
+		n += f.Size
+	}
+	return n
+}
+
+// isTempMetadata reports whether rel is the temp metadata file, which
+// describes the project rather than belongs to it.
+func isTempMetadata(rel string) bool {
+	return rel == ".endmi_meta.json"
+}
+
+// snapshotExcludes reports whether snapshots leave out the entry at path:
+// the temp metadata, so restoring never changes the project's name, pin or
+// TTL, and outputs that can be re-created and would only fill the object
+// store, namely vendor directories, test binaries and compiled programs.
+// Restores leave excluded entries alone too.
+func snapshotExcludes(path, rel string, d fs.DirEntry) bool {
+	if isTempMetadata(rel) {
+		return true
+	}
+	if d.IsDir() {
+		return d.Name() == "vendor"
+	}
+	name := d.Name()
+	if strings.HasSuffix(name, ".test") || strings.HasSuffix(name, ".exe") {
+		return true
+	}
+	return d.Type().IsRegular() && isExecutable(path)
+}
+
+// executableMagic are the leading bytes of ELF, PE and Mach-O files.
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xfe, 0xed, 0xfa, 0xce}, {0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe}, {0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+}
+
+// isExecutable reports whether the file at path is a compiled program.
+func isExecutable(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, 4)
+	n, _ := io.ReadFull(f, head)
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(head[:n], magic) {
+			return true
+		}
+	}
+	return false
+}
+
+func (tcm *TempCodeManager) snapshotsPath() (string, error) {
+	tempDir, err := tcm.GetTempDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(tempDir, SnapshotsDir), nil
+}
+
+// SnapshotTempProject records the current files of a temp project. File
+// contents already stored by earlier snapshots are not stored again.
+func (tcm *TempCodeManager) SnapshotTempProject(projectName, message string) (*Snapshot, error) {
+	project, err := tcm.ResolveTempProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := tcm.ListSnapshots(project.Name)
+	if err != nil {
+		return nil, err
+	}
+	next := 1
+	if len(existing) > 0 {
+		last, _ := strconv.Atoi(existing[len(existing)-1].ID)
+		next = last + 1
+	}
+
+	snap := &Snapshot{
+		ID:        strconv.Itoa(next),
+		Project:   project.Name,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	err = filepath.WalkDir(project.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(project.Path, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if snapshotExcludes(path, rel, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entry := SnapshotEntry{Path: rel, Mode: info.Mode()}
+		switch {
+		case d.IsDir():
+		case info.Mode()&fs.ModeSymlink != 0:
+			if entry.Link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			entry.Size = info.Size()
+			if entry.SHA256, err = storeObject(root, path); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		snap.Files = append(snap.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot '%s': %w", project.Name, err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	dir := snapshotDir(root, project.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, snap.ID+".json"), data, 0644); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// storeObject copies a file into the object store under its SHA-256,
+// unless that content is already there, and returns the hash.
+func storeObject(root, path string) (string, error) {
+	sum, err := checksumFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	object := objectPath(root, sum)
+	if _, err := os.Stat(object); err == nil {
+		return sum, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(object), 0755); err != nil {
+		return "", err
+	}
+
+	// Write under a temporary name so a crash never leaves a truncated
+	// object that later snapshots would trust.
+	tmp := object + ".tmp"
+	if err := copyFile(path, tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return sum, os.Rename(tmp, object)
+}
+
+func snapshotDir(root, projectName string) string {
+	return filepath.Join(root, "projects", projectName)
+}
+
+func objectPath(root, sum string) string {
+	return filepath.Join(root, "objects", sum[:2], sum[2:])
+}
+
+// ListSnapshots returns a temp project's snapshots, oldest first.
+func (tcm *TempCodeManager) ListSnapshots(projectName string) ([]Snapshot, error) {
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := snapshotDir(root, projectName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []Snapshot
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		snap, err := loadSnapshot(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, *snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		a, _ := strconv.Atoi(snaps[i].ID)
+		b, _ := strconv.Atoi(snaps[j].ID)
+		return a < b
+	})
+	return snaps, nil
+}
+
+func loadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// RestoreSnapshot rolls a temp project back to a snapshot: files are put
+// back as recorded and files created since are removed. The current state
+// is snapshotted first and returned, so a restore can itself be undone.
+func (tcm *TempCodeManager) RestoreSnapshot(projectName, id string) (*Snapshot, error) {
+	project, err := tcm.ResolveTempProjectStrict(projectName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, fmt.Errorf("snapshot '%s' of '%s' does not exist", id, project.Name)
+	}
+	snap, err := loadSnapshot(filepath.Join(snapshotDir(root, project.Name), id+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot '%s' of '%s' does not exist", id, project.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Every path must stay inside the project and every object be present
+	// before the project is touched.
+	for _, f := range snap.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return nil, fmt.Errorf("snapshot '%s' is damaged: invalid path %q", id, f.Path)
+		}
+		if !f.Mode.IsRegular() {
+			continue
+		}
+		if len(f.SHA256) != 64 {
+			return nil, fmt.Errorf("snapshot '%s' is damaged: no content recorded for %s", id, f.Path)
+		}
+		info, err := os.Stat(objectPath(root, f.SHA256))
+		if err != nil || info.Size() != f.Size {
+			return nil, fmt.Errorf("snapshot '%s' is damaged: missing content of %s", id, f.Path)
+		}
+	}
+
+	backup, err := tcm.SnapshotTempProject(project.Name, fmt.Sprintf("before restoring snapshot %s", id))
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool, len(snap.Files))
+	for _, f := range snap.Files {
+		keep[f.Path] = true
+	}
+	if err := removeUnlisted(project.Path, keep); err != nil {
+		return backup, err
+	}
+
+	for _, f := range snap.Files {
+		target := filepath.Join(project.Path, filepath.FromSlash(f.Path))
+		switch {
+		case f.Mode.IsDir():
+			// A file or symlink may have taken the directory's place.
+			if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+				os.Remove(target)
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return backup, err
+			}
+		case f.Mode&fs.ModeSymlink != 0:
+			os.Remove(target)
+			if err := os.Symlink(f.Link, target); err != nil {
+				return backup, err
+			}
+		default:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return backup, err
+			}
+			// Remove first so a symlink in the way is replaced, not
+			// followed.
+			os.Remove(target)
+			if err := copyFile(objectPath(root, f.SHA256), target, f.Mode.Perm()); err != nil {
+				return backup, err
+			}
+		}
+	}
+
+	// Directory modes last, as with copyTree.
+	for i := len(snap.Files) - 1; i >= 0; i-- {
+		f := snap.Files[i]
+		if f.Mode.IsDir() {
+			if err := os.Chmod(filepath.Join(project.Path, filepath.FromSlash(f.Path)), f.Mode.Perm()); err != nil {
+				return backup, err
+			}
+		}
+	}
+
+	return backup, nil
+}
+
+// removeUnlisted deletes everything under dir that keep does not list,
+// except what snapshots exclude. Unlisted directories are removed only
+// once empty, so excluded entries inside them, like a compiled program in
+// a new bin directory, survive.
+func removeUnlisted(dir string, keep map[string]bool) error {
+	var files, dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if snapshotExcludes(path, rel, d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case keep[rel]:
+		case d.IsDir():
+			dirs = append(dirs, path)
+		default:
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	// Deepest first, so parents are emptied before they are checked.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(dirs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeSnapshots drops a project's snapshots and any stored content no
+// other snapshot uses.
+func (tcm *TempCodeManager) removeSnapshots(projectName string) error {
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		return err
+	}
+	dir := snapshotDir(root, projectName)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return pruneObjects(root)
+}
+
+// pruneObjects removes stored content that no snapshot refers to.
+func pruneObjects(root string) error {
+	used := make(map[string]bool)
+	projects, err := os.ReadDir(filepath.Join(root, "projects"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, p := range projects {
+		snaps, err := os.ReadDir(filepath.Join(root, "projects", p.Name()))
+		if err != nil {
+			return err
+		}
+		for _, f := range snaps {
+			snap, err := loadSnapshot(filepath.Join(root, "projects", p.Name(), f.Name()))
+			if err != nil {
+				// Keep everything rather than lose content a snapshot we
+				// cannot read might need.
+				return nil
+			}
+			for _, e := range snap.Files {
+				used[e.SHA256] = true
+			}
+		}
+	}
+
+	objects := filepath.Join(root, "objects")
+	return filepath.WalkDir(objects, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(objects, path)
+		if err != nil {
+			return err
+		}
+		if !used[strings.ReplaceAll(filepath.ToSlash(rel), "/", "")] {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// removeProject deletes a temp project along with its snapshots.
+func (tcm *TempCodeManager) removeProject(project TempProjectMetadata) error {
+	if err := os.RemoveAll(project.Path); err != nil {
+		return err
+	}
+	return tcm.removeSnapshots(project.Name)
+}
@@ -0,0 +1,396 @@
+package core
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// newSnapshotWorkspace returns a manager with empty temp projects named
+// names.
+func newSnapshotWorkspace(t *testing.T, names ...string) *TempCodeManager {
+	t.Helper()
+	tcm := &TempCodeManager{App: &App{}, Config: &utils.Config{TempDir: t.TempDir()}}
+	for _, name := range names {
+		dir := filepath.Join(tcm.Config.TempDir, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		meta := TempProjectMetadata{Name: name, CreatedAt: time.Now(), Template: "basic", Path: dir}
+		if err := tcm.saveMetadata(dir, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tcm
+}
+
+// treeEntry is what treeState records for one path.
+type treeEntry struct {
+	Mode    fs.FileMode
+	Content string
+}
+
+// treeState describes every entry under dir, by slash-separated path,
+// including the temp metadata and excluded outputs.
+func treeState(t *testing.T, dir string) map[string]treeEntry {
+	t.Helper()
+	state := make(map[string]treeEntry)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		entry := treeEntry{Mode: info.Mode()}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			entry.Content, err = os.Readlink(path)
+		case info.Mode().IsRegular():
+			var data []byte
+			data, err = os.ReadFile(path)
+			entry.Content = string(data)
+		}
+		state[filepath.ToSlash(rel)] = entry
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// writeTestFiles creates files under dir, keyed by slash-separated path.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func mustDo(t *testing.T, errs ...error) {
+	t.Helper()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestoreSnapshotRoundTrip(t *testing.T) {
+	tcm := newSnapshotWorkspace(t, "proj")
+	dir := filepath.Join(tcm.Config.TempDir, "proj")
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":          "module proj\n",
+		"main.go":         "package main\n",
+		"run.sh":          "#!/bin/sh\necho hi\n",
+		"pkg/util.go":     "package pkg\n",
+		"pkg/deep/a.go":   "package deep\n",
+		"docs/readme.txt": "read me\n",
+	})
+	mustDo(t,
+		os.Chmod(filepath.Join(dir, "run.sh"), 0755),
+		os.Chmod(filepath.Join(dir, "docs"), 0700),
+		os.Mkdir(filepath.Join(dir, "empty"), 0750),
+		os.Symlink("main.go", filepath.Join(dir, "link.go")),
+		os.Symlink("pkg", filepath.Join(dir, "pkglink")),
+	)
+	want := treeState(t, dir)
+
+	snap, err := tcm.SnapshotTempProject("proj", "initial")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change contents, modes and links, remove files and add new ones,
+	// and swap files, directories and links for one another.
+	mustDo(t,
+		os.Remove(filepath.Join(dir, "pkg", "util.go")),
+		os.Remove(filepath.Join(dir, "empty")),
+		os.WriteFile(filepath.Join(dir, "empty"), []byte("was a directory\n"), 0644),
+	)
+	writeTestFiles(t, dir, map[string]string{
+		"main.go":         "package main\n\nfunc main() {}\n",
+		"new.go":          "package main\n",
+		"added/x/y.go":    "package y\n",
+		"pkg/util.go/bad": "file became a directory\n",
+	})
+	mustDo(t,
+		os.Remove(filepath.Join(dir, "pkg", "deep", "a.go")),
+		os.Chmod(filepath.Join(dir, "run.sh"), 0600),
+		os.Chmod(filepath.Join(dir, "docs"), 0755),
+		os.Remove(filepath.Join(dir, "link.go")),
+		os.Symlink("go.mod", filepath.Join(dir, "link.go")),
+		os.Remove(filepath.Join(dir, "pkglink")),
+		os.WriteFile(filepath.Join(dir, "pkglink"), []byte("not a link\n"), 0644),
+	)
+	modified := treeState(t, dir)
+
+	backup, err := tcm.RestoreSnapshot("proj", snap.ID)
+	if err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if got := treeState(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored tree differs from the snapshot:\ngot  %v\nwant %v", got, want)
+	}
+
+	// The backup taken before restoring undoes the restore.
+	if _, err := tcm.RestoreSnapshot("proj", backup.ID); err != nil {
+		t.Fatalf("RestoreSnapshot of the backup: %v", err)
+	}
+	if got := treeState(t, dir); !reflect.DeepEqual(got, modified) {
+		t.Errorf("undoing the restore differs from the modified tree:\ngot  %v\nwant %v", got, modified)
+	}
+}
+
+func TestRestoreSnapshotKeepsExcludedEntries(t *testing.T) {
+	tcm := newSnapshotWorkspace(t, "proj")
+	dir := filepath.Join(tcm.Config.TempDir, "proj")
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":  "module proj\n",
+		"main.go": "package main\n",
+	})
+	snap, err := tcm.SnapshotTempProject("proj", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range snap.Files {
+		if strings.HasPrefix(f.Path, "vendor") || isTempMetadata(f.Path) {
+			t.Errorf("snapshot recorded excluded %s", f.Path)
+		}
+	}
+
+	// Created after the snapshot, so a restore would delete them if it
+	// did not leave excluded entries alone.
+	writeTestFiles(t, dir, map[string]string{
+		"vendor/modules.txt":          "# example.com/lib v1.0.0\n",
+		"vendor/example.com/lib/a.go": "package lib\n",
+		"proj.test":                   "test binary\n",
+		"bin/proj":                    "\x7fELF compiled program\n",
+	})
+	meta, err := tcm.lookupTempProject("proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta.Note = "changed after the snapshot"
+	mustDo(t, tcm.saveMetadata(dir, meta))
+	before := treeState(t, dir)
+
+	if _, err := tcm.RestoreSnapshot("proj", snap.ID); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	after := treeState(t, dir)
+	for _, rel := range []string{
+		"vendor/modules.txt", "vendor/example.com/lib/a.go", "proj.test", "bin/proj", ".endmi_meta.json",
+	} {
+		if after[rel] != before[rel] {
+			t.Errorf("%s changed by the restore: %+v, want %+v", rel, after[rel], before[rel])
+		}
+	}
+}
+
+func TestPruneObjectsKeepsSharedContent(t *testing.T) {
+	tcm := newSnapshotWorkspace(t, "a", "b")
+	writeTestFiles(t, filepath.Join(tcm.Config.TempDir, "a"), map[string]string{
+		"shared.go": "package shared\n",
+		"only_a.go": "package a\n",
+	})
+	writeTestFiles(t, filepath.Join(tcm.Config.TempDir, "b"), map[string]string{
+		"shared.go": "package shared\n",
+		"only_b.go": "package b\n",
+	})
+	snapA, err := tcm.SnapshotTempProject("a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapB, err := tcm.SnapshotTempProject("b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := func(snap *Snapshot) map[string]string {
+		m := make(map[string]string)
+		for _, f := range snap.Files {
+			m[f.Path] = f.SHA256
+		}
+		return m
+	}
+	a, b := sums(snapA), sums(snapB)
+	if a["shared.go"] != b["shared.go"] {
+		t.Fatal("identical files were stored under different objects")
+	}
+	// An interrupted store leaves a temporary file nothing refers to.
+	mustDo(t, os.WriteFile(objectPath(root, a["only_a.go"])+".tmp", []byte("partial"), 0644))
+
+	if err := tcm.removeSnapshots("a"); err != nil {
+		t.Fatalf("removeSnapshots: %v", err)
+	}
+
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+	if exists(objectPath(root, a["only_a.go"])) {
+		t.Error("content only a's snapshot used was kept")
+	}
+	if exists(objectPath(root, a["only_a.go"]) + ".tmp") {
+		t.Error("unreferenced temporary object was kept")
+	}
+	for _, rel := range []string{"shared.go", "only_b.go"} {
+		if !exists(objectPath(root, b[rel])) {
+			t.Errorf("content of %s, still used by b's snapshot, was removed", rel)
+		}
+	}
+	if _, err := tcm.RestoreSnapshot("b", snapB.ID); err != nil {
+		t.Errorf("restoring b after pruning a: %v", err)
+	}
+}
+
+func TestPruneObjectsKeepsEverythingWithUnreadableSnapshot(t *testing.T) {
+	tcm := newSnapshotWorkspace(t, "a", "b")
+	writeTestFiles(t, filepath.Join(tcm.Config.TempDir, "a"), map[string]string{"a.go": "package a\n"})
+	snap, err := tcm.SnapshotTempProject("a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(snapshotDir(root, "b"), "1.json")
+	mustDo(t, os.MkdirAll(filepath.Dir(broken), 0755), os.WriteFile(broken, []byte("{not json"), 0644))
+
+	// Dropping a's snapshot would normally free a.go's content, but b's
+	// unreadable snapshot might refer to it.
+	mustDo(t, os.Remove(filepath.Join(snapshotDir(root, "a"), snap.ID+".json")))
+	if err := pruneObjects(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(objectPath(root, snap.Files[0].SHA256)); err != nil {
+		t.Errorf("object removed although a snapshot could not be read: %v", err)
+	}
+}
+
+func TestRestoreSnapshotRejectsDamagedSnapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage func(t *testing.T, root string, snap *Snapshot)
+	}{
+		{
+			name: "missing object",
+			damage: func(t *testing.T, root string, snap *Snapshot) {
+				mustDo(t, os.Remove(objectPath(root, snapshotEntry(t, snap, "main.go").SHA256)))
+			},
+		},
+		{
+			name: "truncated object",
+			damage: func(t *testing.T, root string, snap *Snapshot) {
+				mustDo(t, os.Truncate(objectPath(root, snapshotEntry(t, snap, "main.go").SHA256), 3))
+			},
+		},
+		{
+			name: "file without content",
+			damage: func(t *testing.T, root string, snap *Snapshot) {
+				rewriteSnapshot(t, root, snap, func(s *Snapshot) {
+					for i := range s.Files {
+						s.Files[i].SHA256 = ""
+					}
+				})
+			},
+		},
+		{
+			name: "path outside the project",
+			damage: func(t *testing.T, root string, snap *Snapshot) {
+				rewriteSnapshot(t, root, snap, func(s *Snapshot) {
+					s.Files = append(s.Files, SnapshotEntry{Path: "../escaped.go", Mode: 0644, Size: 13,
+						SHA256: snapshotEntry(t, snap, "main.go").SHA256})
+				})
+			},
+		},
+		{
+			name: "invalid JSON",
+			damage: func(t *testing.T, root string, snap *Snapshot) {
+				mustDo(t, os.WriteFile(filepath.Join(snapshotDir(root, "proj"), snap.ID+".json"), []byte(`{"files": [`), 0644))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcm := newSnapshotWorkspace(t, "proj")
+			dir := filepath.Join(tcm.Config.TempDir, "proj")
+			writeTestFiles(t, dir, map[string]string{"go.mod": "module proj\n", "main.go": "package main\n"})
+			snap, err := tcm.SnapshotTempProject("proj", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			root, err := tcm.snapshotsPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			writeTestFiles(t, dir, map[string]string{"main.go": "package main\n\nfunc main() {}\n", "new.go": "package main\n"})
+			before := treeState(t, dir)
+			tt.damage(t, root, snap)
+
+			if _, err := tcm.RestoreSnapshot("proj", snap.ID); err == nil {
+				t.Fatal("RestoreSnapshot accepted a damaged snapshot")
+			}
+			if got := treeState(t, dir); !reflect.DeepEqual(got, before) {
+				t.Errorf("project changed by a rejected restore:\ngot  %v\nwant %v", got, before)
+			}
+			if _, err := os.Lstat(filepath.Join(tcm.Config.TempDir, "escaped.go")); err == nil {
+				t.Error("restore wrote outside the project")
+			}
+			entries, err := os.ReadDir(snapshotDir(root, "proj"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("a rejected restore left %d snapshots, want 1", len(entries))
+			}
+		})
+	}
+}
+
+// snapshotEntry returns the entry of snap for rel.
+func snapshotEntry(t *testing.T, snap *Snapshot, rel string) SnapshotEntry {
+	t.Helper()
+	for _, f := range snap.Files {
+		if f.Path == rel {
+			return f
+		}
+	}
+	t.Fatalf("snapshot has no %s", rel)
+	return SnapshotEntry{}
+}
+
+// rewriteSnapshot saves snap again after edit changes a copy of it.
+func rewriteSnapshot(t *testing.T, root string, snap *Snapshot, edit func(*Snapshot)) {
+	t.Helper()
+	copied := *snap
+	copied.Files = append([]SnapshotEntry(nil), snap.Files...)
+	edit(&copied)
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustDo(t, os.WriteFile(filepath.Join(snapshotDir(root, snap.Project), snap.ID+".json"), data, 0644))
+}
@@ -0,0 +1,363 @@
+package core
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stdImports maps the package names snippets commonly use without
+// importing them to their standard library import paths. Ambiguous names
+// pick the usual choice, e.g. rand is math/rand.
+var stdImports = map[string]string{
+	"atomic":    "sync/atomic",
+	"base64":    "encoding/base64",
+	"big":       "math/big",
+	"binary":    "encoding/binary",
+	"bits":      "math/bits",
+	"bufio":     "bufio",
+	"bytes":     "bytes",
+	"cmp":       "cmp",
+	"context":   "context",
+	"csv":       "encoding/csv",
+	"errors":    "errors",
+	"exec":      "os/exec",
+	"filepath":  "path/filepath",
+	"flag":      "flag",
+	"fmt":       "fmt",
+	"fs":        "io/fs",
+	"hash":      "hash",
+	"heap":      "container/heap",
+	"hex":       "encoding/hex",
+	"http":      "net/http",
+	"io":        "io",
+	"iter":      "iter",
+	"json":      "encoding/json",
+	"list":      "container/list",
+	"log":       "log",
+	"maps":      "maps",
+	"math":      "math",
+	"md5":       "crypto/md5",
+	"net":       "net",
+	"netip":     "net/netip",
+	"os":        "os",
+	"path":      "path",
+	"rand":      "math/rand",
+	"reflect":   "reflect",
+	"regexp":    "regexp",
+	"runtime":   "runtime",
+	"sha1":      "crypto/sha1",
+	"sha256":    "crypto/sha256",
+	"sha512":    "crypto/sha512",
+	"signal":    "os/signal",
+	"slices":    "slices",
+	"slog":      "log/slog",
+	"sort":      "sort",
+	"strconv":   "strconv",
+	"strings":   "strings",
+	"sync":      "sync",
+	"tabwriter": "text/tabwriter",
+	"template":  "text/template",
+	"time":      "time",
+	"unicode":   "unicode",
+	"unsafe":    "unsafe",
+	"url":       "net/url",
+	"utf8":      "unicode/utf8",
+	"xml":       "encoding/xml",
+}
+
+// SnippetOptions controls RunSnippet.
+type SnippetOptions struct {
+	RunOptions
+	// Filename names the snippet in compiler errors, e.g. "snippet.go".
+	Filename string
+	// Keep leaves the snippet's project in the temp workspace after the run.
+	Keep bool
+	// Scratch adds the snippet to the shared scratch module instead of
+	// creating a module for it.
+	Scratch bool
+}
+
+// snippetTemplate is the one-file template a snippet project is created
+// from. It is not registered, so it never shows up in template lists.
+type snippetTemplate struct {
+	source string
+}
+
+func (t snippetTemplate) Name() string           { return "snippet" }
+func (t snippetTemplate) Description() string    { return "A Go snippet run with 'endmi temp run'" }
+func (t snippetTemplate) RootDir() string        { return "" }
+func (t snippetTemplate) Dependencies() []string { return nil }
+func (t snippetTemplate) Files(string) map[string]string {
+	return map[string]string{"main.go": t.source}
+}
+
+// RunSnippet turns Go source into a temp project, runs it and, unless
+// opts.Keep is set, removes the project again. It returns the project's name
+// and the program's exit code.
+func (tcm *TempCodeManager) RunSnippet(src []byte, opts SnippetOptions) (string, int, error) {
+	filename := opts.Filename
+	if filename == "" {
+		filename = "snippet.go"
+	}
+	snippet, err := PrepareSnippet(filename, string(src))
+	if err != nil {
+		return "", -1, err
+	}
+
+	name := fmt.Sprintf("snippet_%d", time.Now().UnixNano())
+	var report *Report
+	if opts.Scratch {
+		report, err = tcm.CreateScratchSnippet(name, snippet.Source, TempOptions{})
+	} else {
+		report, err = tcm.CreateTempProject(snippetTemplate{source: snippet.Source}, name, TempOptions{})
+	}
+	if report != nil && !opts.Keep {
+		defer os.RemoveAll(report.Path)
+	}
+	if err != nil {
+		return name, -1, err
+	}
+
+	opts.RunOptions.mapBuildOutput = snippet.MapBuildOutput
+	code, err := tcm.App.runProgram(report.Path, opts.RunOptions)
+	return name, code, err
+}
+
+// Snippet is a snippet turned into a complete main.go.
+type Snippet struct {
+	// Source is the main.go to write.
+	Source string
+
+	filename string
+	// lines maps each line of Source, from the first, to the snippet line
+	// it came from, or 0 for lines endmi added.
+	lines []int
+}
+
+// buildPosition matches main.go positions in compiler output.
+var buildPosition = regexp.MustCompile(`(?m)(^|[\s(])(?:\./)?main\.go:(\d+)`)
+
+// MapBuildOutput rewrites main.go positions in compiler output to the
+// snippet's own file name and lines.
+func (s *Snippet) MapBuildOutput(out []byte) []byte {
+	return buildPosition.ReplaceAllFunc(out, func(m []byte) []byte {
+		sub := buildPosition.FindSubmatch(m)
+		line, _ := strconv.Atoi(string(sub[2]))
+		if line < 1 || line > len(s.lines) || s.lines[line-1] == 0 {
+			return m
+		}
+		return []byte(fmt.Sprintf("%s%s:%d", sub[1], s.filename, s.lines[line-1]))
+	})
+}
+
+// newSnippet strips the //line directives PrepareSnippet placed in src,
+// recording the line mapping they described instead.
+func newSnippet(filename, src string) *Snippet {
+	s := &Snippet{filename: filename}
+	prefix := fmt.Sprintf("//line %s:", filename)
+
+	var b strings.Builder
+	next := 0
+	for _, line := range strings.SplitAfter(src, "\n") {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			if n, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil {
+				next = n
+				continue
+			}
+		}
+		if line == "" {
+			continue
+		}
+		b.WriteString(line)
+		s.lines = append(s.lines, next)
+		if next > 0 {
+			next++
+		}
+	}
+	s.Source = b.String()
+	return s
+}
+
+// PrepareSnippet turns a snippet into a complete main.go. The snippet may be
+// a full file, declarations without a package clause, or bare statements,
+// optionally preceded by imports, which are wrapped in func main. Missing
+// standard library imports are added and unused ones removed. The returned
+// Snippet can map compiler errors back to the snippet's own lines.
+func PrepareSnippet(filename, src string) (*Snippet, error) {
+	filename = filepath.Base(filename)
+	directive := func(line int) string {
+		return fmt.Sprintf("//line %s:%d\n", filename, line)
+	}
+
+	imports, rest, restLine := splitLeadingImports(src)
+	candidates := []string{
+		src,
+		"package main\n" + directive(1) + src,
+		"package main\n\n" + imports + "\nfunc main() {\n" + directive(restLine) + strings.TrimRight(rest, "\n") + "\n}\n",
+	}
+
+	var file *ast.File
+	var fset *token.FileSet
+	var candidate string
+	var errs []error
+	for i, c := range candidates {
+		fs := token.NewFileSet()
+		f, err := parser.ParseFile(fs, filename, c, parser.ParseComments)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// Declarations only count as a program if they declare main.
+		if i == 1 && !declaresMain(f) {
+			errs = append(errs, fmt.Errorf("%s: no func main", filename))
+			continue
+		}
+		file, fset, candidate = f, fs, c
+		break
+	}
+	if file == nil {
+		if strings.HasPrefix(strings.TrimSpace(src), "package ") {
+			return nil, errs[0]
+		}
+		return nil, errs[len(errs)-1]
+	}
+
+	// Cut the candidate into its package clause and everything after the
+	// imports, then put a fixed import block in between.
+	tf := fset.File(file.Pos())
+	headerEnd := tf.Offset(file.Name.End())
+	bodyStart := headerEnd
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			bodyStart = tf.Offset(gen.End())
+		}
+	}
+	if i := strings.IndexByte(candidate[bodyStart:], '\n'); i >= 0 {
+		bodyStart += i + 1
+	} else {
+		bodyStart = len(candidate)
+	}
+	bodyLine := fset.PositionFor(tf.Pos(bodyStart), true).Line
+	if bodyStart == len(candidate) {
+		bodyLine++
+	}
+	// Blank lines left between the imports and the body are dropped.
+	for strings.HasPrefix(candidate[bodyStart:], "\n") {
+		bodyStart++
+		bodyLine++
+	}
+
+	var b strings.Builder
+	b.WriteString(candidate[:headerEnd])
+	b.WriteString("\n\n")
+	if paths := fixImports(file); len(paths) > 0 {
+		b.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%s\n", p)
+		}
+		b.WriteString(")\n\n")
+	}
+	// Parse errors above already point at snippet lines through the
+	// directives; the written file is kept free of them.
+	b.WriteString(directive(bodyLine))
+	b.WriteString(candidate[bodyStart:])
+	return newSnippet(filename, b.String()), nil
+}
+
+// splitLeadingImports separates import declarations at the top of a bare
+// snippet from the statements after them, returning the line the
+// statements start on.
+func splitLeadingImports(src string) (string, string, int) {
+	lines := strings.SplitAfter(src, "\n")
+	inBlock := false
+	end := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			if strings.HasPrefix(trimmed, ")") {
+				inBlock = false
+			}
+		case strings.HasPrefix(trimmed, "import ("):
+			inBlock = !strings.Contains(trimmed, ")")
+		case strings.HasPrefix(trimmed, "import "), trimmed == "", strings.HasPrefix(trimmed, "//"):
+		default:
+			return strings.Join(lines[:end], ""), strings.Join(lines[i:], ""), i + 1
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "//") {
+			end = i + 1
+		}
+	}
+	return strings.Join(lines[:end], ""), strings.Join(lines[end:], ""), end + 1
+}
+
+// declaresMain reports whether f declares a top-level func main.
+func declaresMain(f *ast.File) bool {
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// fixImports returns the quoted import specs f needs: its own imports minus
+// unused standard library ones, plus standard packages it refers to without
+// importing.
+func fixImports(f *ast.File) []string {
+	unresolved := make(map[*ast.Ident]bool, len(f.Unresolved))
+	for _, id := range f.Unresolved {
+		unresolved[id] = true
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && unresolved[id] {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+
+	var specs []string
+	provided := make(map[string]bool)
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+
+		isStd := !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+		if isStd && name != "_" && name != "." && !used[name] {
+			continue
+		}
+		provided[name] = true
+
+		spec := imp.Path.Value
+		if imp.Name != nil {
+			spec = imp.Name.Name + " " + spec
+		}
+		specs = append(specs, spec)
+	}
+
+	for name := range used {
+		if path, ok := stdImports[name]; ok && !provided[name] {
+			specs = append(specs, strconv.Quote(path))
+		}
+	}
+	sort.Strings(specs)
+	return specs
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// markerLine returns the 1-based line of s that contains marker, or 0.
+func markerLine(s, marker string) int {
+	for i, line := range strings.Split(s, "\n") {
+		if strings.Contains(line, marker) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func TestPrepareSnippet(t *testing.T) {
+	const marker = "// here"
+
+	tests := []struct {
+		name        string
+		src         string
+		wantImports []string
+		wantErr     bool
+	}{
+		{
+			name:        "full file",
+			src:         "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(1) // here\n}\n",
+			wantImports: []string{`"fmt"`},
+		},
+		{
+			name:        "full file with unused and missing imports",
+			src:         "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tstrings.ToUpper(\"x\") // here\n\tfmt.Println()\n}\n",
+			wantImports: []string{`"fmt"`, `"strings"`},
+		},
+		{
+			name:        "declarations without package clause",
+			src:         "func hello() string { return \"hi\" }\n\nfunc main() {\n\tfmt.Println(hello()) // here\n}\n",
+			wantImports: []string{`"fmt"`},
+		},
+		{
+			name:        "bare statements",
+			src:         "x := 1\nfmt.Println(x) // here\n",
+			wantImports: []string{`"fmt"`},
+		},
+		{
+			name:        "bare statements after imports",
+			src:         "import \"os\"\n\n\nname, _ := os.Hostname()\njson.NewEncoder(os.Stdout).Encode(name) // here\n",
+			wantImports: []string{`"encoding/json"`, `"os"`},
+		},
+		{
+			name:        "aliased and third-party imports are kept",
+			src:         "import (\n\tstr \"strings\"\n\t\"github.com/google/uuid\"\n)\nfmt.Println(str.ToUpper(\"x\"), uuid.New()) // here\n",
+			wantImports: []string{`"fmt"`, `"github.com/google/uuid"`, `str "strings"`},
+		},
+		{
+			name:        "local names shadow packages",
+			src:         "strings := []string{\"a\"}\n_ = len(strings) // here\n",
+			wantImports: nil,
+		},
+		{
+			name:    "invalid full file",
+			src:     "package main\n\nfunc main() {\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid statements",
+			src:     "x := \n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippet, err := PrepareSnippet("dir/snippet.go", tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrepareSnippet error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if strings.Contains(snippet.Source, "//line") {
+				t.Errorf("Source contains a //line directive:\n%s", snippet.Source)
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), "main.go", snippet.Source, parser.ImportsOnly)
+			if err != nil {
+				t.Fatalf("Source does not parse: %v\n%s", err, snippet.Source)
+			}
+			if f.Name.Name != "main" {
+				t.Errorf("package = %s, want main", f.Name.Name)
+			}
+			var imports []string
+			for _, imp := range f.Imports {
+				spec := imp.Path.Value
+				if imp.Name != nil {
+					spec = imp.Name.Name + " " + spec
+				}
+				imports = append(imports, spec)
+			}
+			if !reflect.DeepEqual(imports, tt.wantImports) {
+				t.Errorf("imports = %v, want %v", imports, tt.wantImports)
+			}
+
+			srcLine, outLine := markerLine(tt.src, marker), markerLine(snippet.Source, marker)
+			if outLine == 0 {
+				t.Fatalf("Source lost the marked line:\n%s", snippet.Source)
+			}
+			out := fmt.Sprintf("# command-line-arguments\n./main.go:%d:2: undefined: y\n", outLine)
+			want := fmt.Sprintf("# command-line-arguments\nsnippet.go:%d:2: undefined: y\n", srcLine)
+			if got := string(snippet.MapBuildOutput([]byte(out))); got != want {
+				t.Errorf("MapBuildOutput = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSnippetMapBuildOutputKeepsAddedLines(t *testing.T) {
+	snippet, err := PrepareSnippet("snippet.go", "fmt.Println(1)\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Line 1 is the package clause endmi added; there is no snippet line
+	// to point at, so it is left alone, as are other files' positions.
+	out := "main.go:1:1: error\nother.go:3:1: error\n"
+	if got := string(snippet.MapBuildOutput([]byte(out))); got != out {
+		t.Errorf("MapBuildOutput = %q, want %q", got, out)
+	}
+}
+
+func TestFixImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "adds missing standard imports",
+			src:  "package main\nfunc main() { fmt.Println(strings.ToUpper(\"x\")); _ = http.StatusOK }",
+			want: []string{`"fmt"`, `"net/http"`, `"strings"`},
+		},
+		{
+			name: "removes unused standard imports",
+			src:  "package main\nimport (\"fmt\"; \"os\")\nfunc main() { fmt.Println() }",
+			want: []string{`"fmt"`},
+		},
+		{
+			name: "keeps third-party, blank and dot imports",
+			src:  "package main\nimport (\"example.com/lib\"; _ \"embed\"; . \"math\")\nfunc main() {}",
+			want: []string{`"example.com/lib"`, `. "math"`, `_ "embed"`},
+		},
+		{
+			name: "keeps used aliases",
+			src:  "package main\nimport (r \"crypto/rand\"; m \"math/rand\")\nfunc main() { r.Read(nil) }",
+			want: []string{`r "crypto/rand"`},
+		},
+		{
+			name: "does not duplicate imported names",
+			src:  "package main\nimport rand \"crypto/rand\"\nfunc main() { rand.Read(nil) }",
+			want: []string{`rand "crypto/rand"`},
+		},
+		{
+			name: "ignores unknown packages and local values",
+			src:  "package main\ntype T struct{ x int }\nfunc main() { var t T; _ = t.x; foo.Bar() }",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "main.go", tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fixImports(f); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fixImports = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
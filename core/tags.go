@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxTagLength keeps tags short enough to list several on one line.
+const maxTagLength = 32
+
+// ValidateTag checks that tag is a usable temp project tag: lowercase ASCII
+// letters, digits, '-', '_' and '.', at most maxTagLength long.
+func ValidateTag(tag string) error {
+	switch {
+	case tag == "":
+		return fmt.Errorf("invalid tag: tag is empty")
+	case len(tag) > maxTagLength:
+		return fmt.Errorf("invalid tag %q: tag is longer than %d characters", tag, maxTagLength)
+	}
+	for _, r := range tag {
+		if !isNameChar(r) {
+			return fmt.Errorf("invalid tag %q: tag must not contain %q", tag, r)
+		}
+	}
+	return nil
+}
+
+// ParseTags splits comma-separated tag lists, lowercases them and drops
+// duplicates, so "HTTP,retry" and "http" give [http retry].
+func ParseTags(lists ...string) ([]string, error) {
+	var tags []string
+	for _, list := range lists {
+		for _, tag := range strings.Split(list, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			if err := ValidateTag(tag); err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return mergeTags(nil, tags), nil
+}
+
+// mergeTags adds tags missing from list and returns it sorted.
+func mergeTags(list, tags []string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, tag := range list {
+		seen[tag] = true
+	}
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			list = append(list, tag)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// HasTag reports whether the project is tagged with tag.
+func (p TempProjectMetadata) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagTempProject adds and removes tags of a temporary project and returns
+// the tags it ends up with.
+func (tcm *TempCodeManager) TagTempProject(projectName string, add, remove []string) ([]string, error) {
+	project, err := tcm.ResolveTempProject(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	drop := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		drop[tag] = true
+	}
+	var tags []string
+	for _, tag := range mergeTags(project.Tags, add) {
+		if !drop[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	project.Tags = tags
+	return tags, tcm.saveMetadata(project.Path, project)
+}
+
+// SetNote replaces the note of a temporary project; an empty note removes
+// it.
+func (tcm *TempCodeManager) SetNote(projectName, note string) error {
+	project, err := tcm.ResolveTempProject(projectName)
+	if err != nil {
+		return err
+	}
+
+	project.Note = strings.TrimSpace(note)
+	return tcm.saveMetadata(project.Path, project)
+}
+
+// TempFilter selects temp projects for listing. Zero fields match every
+// project.
+type TempFilter struct {
+	// Tags must all be on a project for it to match.
+	Tags []string
+	// Template is the name of the template the project was created from.
+	Template string
+	// Since matches projects created within this long before now.
+	Since time.Duration
+}
+
+// Match reports whether p passes the filter at time now.
+func (f TempFilter) Match(p TempProjectMetadata, now time.Time) bool {
+	for _, tag := range f.Tags {
+		if !p.HasTag(tag) {
+			return false
+		}
+	}
+	if f.Template != "" && !strings.EqualFold(p.Template, f.Template) {
+		return false
+	}
+	if f.Since > 0 && p.CreatedAt.Before(now.Add(-f.Since)) {
+		return false
+	}
+	return true
+}
+
+// FilterTempProjects returns the projects matching f, in their original
+// order.
+func FilterTempProjects(projects []TempProjectMetadata, f TempFilter) []TempProjectMetadata {
+	now := time.Now()
+	var matched []TempProjectMetadata
+	for _, p := range projects {
+		if f.Match(p, now) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
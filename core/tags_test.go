@@ -0,0 +1,76 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		lists   []string
+		want    []string
+		wantErr bool
+	}{
+		{"none", nil, nil, false},
+		{"empty list", []string{""}, nil, false},
+		{"single", []string{"http"}, []string{"http"}, false},
+		{"sorted and lowercased", []string{"Retry,HTTP"}, []string{"http", "retry"}, false},
+		{"spaces and empty entries", []string{" a , ,b,"}, []string{"a", "b"}, false},
+		{"several lists deduplicated", []string{"HTTP,retry", "http", "v1.2_x-y"}, []string{"http", "retry", "v1.2_x-y"}, false},
+		{"longest tag", []string{strings.Repeat("t", maxTagLength)}, []string{strings.Repeat("t", maxTagLength)}, false},
+		{"too long", []string{strings.Repeat("t", maxTagLength+1)}, nil, true},
+		{"space inside", []string{"two words"}, nil, true},
+		{"invalid character", []string{"ok,bad!"}, nil, true},
+		{"non-ASCII", []string{"café"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTags(tt.lists...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTags(%q) error = %v, want error %v", tt.lists, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTags(%q) = %q, want %q", tt.lists, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTempFilterMatch(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	project := TempProjectMetadata{
+		Name:      "temp_1",
+		Template:  "web",
+		Tags:      []string{"http", "retry"},
+		CreatedAt: now.Add(-48 * time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		filter TempFilter
+		want   bool
+	}{
+		{"zero filter", TempFilter{}, true},
+		{"one tag", TempFilter{Tags: []string{"http"}}, true},
+		{"all tags", TempFilter{Tags: []string{"retry", "http"}}, true},
+		{"missing tag", TempFilter{Tags: []string{"http", "grpc"}}, false},
+		{"template", TempFilter{Template: "web"}, true},
+		{"template ignores case", TempFilter{Template: "WEB"}, true},
+		{"other template", TempFilter{Template: "cli"}, false},
+		{"created within since", TempFilter{Since: 72 * time.Hour}, true},
+		{"created exactly since ago", TempFilter{Since: 48 * time.Hour}, true},
+		{"created before since", TempFilter{Since: 24 * time.Hour}, false},
+		{"all fields", TempFilter{Tags: []string{"http"}, Template: "web", Since: 72 * time.Hour}, true},
+		{"all fields but one", TempFilter{Tags: []string{"http"}, Template: "cli", Since: 72 * time.Hour}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(project, now); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
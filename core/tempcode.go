@@ -2,9 +2,12 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/dlcuy22/endmi/extensions"
@@ -14,6 +17,8 @@ import (
 // TempCodeManager handles temporary code workspace operations
 type TempCodeManager struct {
 	App *App
+	// Config overrides ~/.endmi/endmi.json when set
+	Config *utils.Config
 }
 
 // TempProjectMetadata stores metadata about a temporary project
@@ -22,31 +27,52 @@ type TempProjectMetadata struct {
 	CreatedAt time.Time `json:"created_at"`
 	Template  string    `json:"template"`
 	Path      string    `json:"path"`
+	// LastUsedAt is bumped whenever endmi runs the project
+	LastUsedAt time.Time `json:"last_used_at,omitempty"`
+	// TTL overrides the configured TempTTL for this project, e.g. "3d"
+	TTL string `json:"ttl,omitempty"`
+	// Scratch marks a snippet living as a package of the shared scratch
+	// module rather than as its own module
+	Scratch bool `json:"scratch,omitempty"`
+	// Pinned projects survive clean, gc and quota cleanup, and need force
+	// to be deleted
+	Pinned bool `json:"pinned,omitempty"`
+	// Tags and Note say what the project is for, e.g. [http] and
+	// "testing retry lib"
+	Tags []string `json:"tags,omitempty"`
+	Note string   `json:"note,omitempty"`
+	// Parent is the project this one was cloned from
+	Parent string `json:"parent,omitempty"`
 }
 
-// loadConfig loads the endmi configuration
-func loadConfig() (*utils.Config, error) {
-	configPath, err := utils.GetConfigFilePath()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config path: %w", err)
-	}
-
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+// ErrProjectNotFound is returned when no temp project has the given name.
+var ErrProjectNotFound = errors.New("temp project not found")
+
+// ErrPinned is returned when deleting a pinned temp project without force.
+var ErrPinned = errors.New("temp project is pinned")
+
+// TempOptions holds per-project settings for CreateTempProject
+type TempOptions struct {
+	// TTL, e.g. "3d", after which `temp gc` may remove the project. Empty
+	// means the configured TempTTL applies.
+	TTL string
+	// Tags and Note are recorded in the project's metadata. Tags are
+	// expected to be normalized by ParseTags.
+	Tags []string
+	Note string
+}
 
-	var cfg utils.Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+// config returns the override config or loads the user's config file
+func (tcm *TempCodeManager) config() (*utils.Config, error) {
+	if tcm.Config != nil {
+		return tcm.Config, nil
 	}
-
-	return &cfg, nil
+	return utils.LoadConfig()
 }
 
 // GetTempDir returns the configured temporary directory path
 func (tcm *TempCodeManager) GetTempDir() (string, error) {
-	cfg, err := loadConfig()
+	cfg, err := tcm.config()
 	if err != nil {
 		return "", err
 	}
@@ -60,77 +86,60 @@ func (tcm *TempCodeManager) GetTempDir() (string, error) {
 }
 
 // CreateTempProject creates a new temporary project in the temp workspace
-func (tcm *TempCodeManager) CreateTempProject(template extensions.Template, projectName string) (string, error) {
+func (tcm *TempCodeManager) CreateTempProject(template extensions.Template, projectName string, opts TempOptions) (*Report, error) {
+	if opts.TTL != "" {
+		if _, err := utils.ParseDuration(opts.TTL); err != nil {
+			return nil, fmt.Errorf("invalid ttl: %w", err)
+		}
+	}
+	for _, tag := range opts.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return nil, err
+		}
+	}
+
 	tempDir, err := tcm.GetTempDir()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Generate unique project name if not provided
 	if projectName == "" {
 		projectName = fmt.Sprintf("temp_%d", time.Now().Unix())
 	}
-
-	projectPath := filepath.Join(tempDir, projectName)
-
-	// Check if project already exists
-	if _, err := os.Stat(projectPath); err == nil {
-		return "", fmt.Errorf("temp project '%s' already exists", projectName)
-	}
-
-	// Create project using the App's CreateProject method
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create project directory: %w", err)
-	}
-
-	// Store original directory since CreateProject expects relative path
-	originalApp := *tcm.App
-	projectApp := &App{Output: originalApp.Output}
-
-	// Create the project structure
-	baseDir := filepath.Join(projectPath, template.RootDir())
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return "", err
-	}
-
-	if err := projectApp.runCommandWithOutput("go", projectPath, "mod", "init", projectName); err != nil {
-		return "", err
-	}
-
-	for rel, content := range template.Files(projectName) {
-		fullPath := filepath.Join(baseDir, rel)
-		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			return "", err
-		}
-		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
-			return "", err
-		}
-	}
-
-	for _, dep := range template.Dependencies() {
-		if err := projectApp.runCommandWithOutput("go", projectPath, "get", dep); err != nil {
-			return "", err
-		}
+	if err := ValidateProjectName(projectName); err != nil {
+		return nil, err
 	}
 
-	if err := projectApp.runCommandWithOutput("go", projectPath, "mod", "tidy"); err != nil {
-		return "", err
-	}
+	projectPath := filepath.Join(tempDir, projectName)
 
-	// Save metadata
-	metadata := TempProjectMetadata{
-		Name:      projectName,
-		CreatedAt: time.Now(),
-		Template:  template.Name(),
-		Path:      projectPath,
+	// Check if project already exists, including as a scratch snippet
+	if _, err := tcm.lookupTempProject(projectName); err == nil {
+		return nil, fmt.Errorf("%w: temp project '%s'", ErrProjectExists, projectName)
 	}
 
-	if err := tcm.saveMetadata(projectPath, metadata); err != nil {
-		// Non-fatal: project is created, metadata is just informational
-		fmt.Printf("Warning: failed to save metadata: %v\n", err)
+	if err := tcm.checkQuota(); err != nil {
+		return nil, err
 	}
 
-	return projectPath, nil
+	spec := ProjectSpec{Name: projectName, Dir: projectPath, ModulePath: projectName}
+	return tcm.App.create(template, spec, func(p *Pipeline) error {
+		return p.InsertAfter(PhaseMetadata, Step{
+			Name: PhaseTempMetadata,
+			Run: func(ctx *BuildContext) error {
+				return tcm.saveMetadata(ctx.Path, TempProjectMetadata{
+					Name:      ctx.Name,
+					CreatedAt: time.Now(),
+					Template:  ctx.Template.Name(),
+					Path:      ctx.Path,
+					TTL:       opts.TTL,
+					Tags:      opts.Tags,
+					Note:      strings.TrimSpace(opts.Note),
+				})
+			},
+			Optional: true, // the project is usable without it
+		})
+	})
 }
 
 // saveMetadata saves project metadata to a .endmi_meta.json file
@@ -159,98 +168,217 @@ func (tcm *TempCodeManager) loadMetadata(projectPath string) (*TempProjectMetada
 	return &metadata, nil
 }
 
-// ListTempProjects returns a list of all temporary projects
+// lookupTempProject finds the temp project or scratch snippet named exactly
+// name.
+func (tcm *TempCodeManager) lookupTempProject(name string) (TempProjectMetadata, error) {
+	tempDir, err := tcm.GetTempDir()
+	if err != nil {
+		return TempProjectMetadata{}, err
+	}
+
+	for _, dir := range []string{tempDir, filepath.Join(tempDir, ScratchDir)} {
+		projectPath := filepath.Join(dir, name)
+		if name == ScratchDir || filepath.Dir(projectPath) != filepath.Clean(dir) {
+			break
+		}
+		info, err := os.Stat(projectPath)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		return tcm.projectMetadata(projectPath, info, dir != tempDir), nil
+	}
+	return TempProjectMetadata{}, fmt.Errorf("%w: '%s'", ErrProjectNotFound, name)
+}
+
+// projectMetadata loads a project's metadata, falling back to what the
+// directory itself tells for projects without any.
+func (tcm *TempCodeManager) projectMetadata(projectPath string, info os.FileInfo, scratch bool) TempProjectMetadata {
+	if metadata, err := tcm.loadMetadata(projectPath); err == nil {
+		return *metadata
+	}
+
+	template := "unknown"
+	if scratch {
+		template = snippetTemplate{}.Name()
+	}
+	return TempProjectMetadata{
+		Name:      info.Name(),
+		CreatedAt: info.ModTime(),
+		Template:  template,
+		Path:      projectPath,
+		Scratch:   scratch,
+	}
+}
+
+// MostRecentTempProject returns the temp project that was used or created
+// last.
+func (tcm *TempCodeManager) MostRecentTempProject() (TempProjectMetadata, error) {
+	return tcm.recentTempProject(1)
+}
+
+// RecentTempProjects returns all temp projects, most recently used first.
+func (tcm *TempCodeManager) RecentTempProjects() ([]TempProjectMetadata, error) {
+	projects, err := tcm.ListTempProjects()
+	if err != nil {
+		return nil, err
+	}
+	sortByLastUsed(projects)
+	return projects, nil
+}
+
+// sortByLastUsed orders projects most recently used first, by name on ties.
+func sortByLastUsed(projects []TempProjectMetadata) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i].LastUsed(), projects[j].LastUsed()
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return projects[i].Name < projects[j].Name
+	})
+}
+
+// LastUsed returns when the project was last run, or its creation time if
+// it never was.
+func (p TempProjectMetadata) LastUsed() time.Time {
+	if p.LastUsedAt.After(p.CreatedAt) {
+		return p.LastUsedAt
+	}
+	return p.CreatedAt
+}
+
+// touch records that the project was just used.
+func (tcm *TempCodeManager) touch(project TempProjectMetadata) error {
+	project.LastUsedAt = time.Now()
+	return tcm.saveMetadata(project.Path, project)
+}
+
+// ListTempProjects returns a list of all temporary projects, including
+// snippets in the scratch module
 func (tcm *TempCodeManager) ListTempProjects() ([]TempProjectMetadata, error) {
 	tempDir, err := tcm.GetTempDir()
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := os.ReadDir(tempDir)
+	projects, err := tcm.listProjectDirs(tempDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchPath := filepath.Join(tempDir, ScratchDir)
+	if _, err := os.Stat(scratchPath); err == nil {
+		snippets, err := tcm.listProjectDirs(scratchPath, true)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, snippets...)
+	}
+
+	return projects, nil
+}
+
+// listProjectDirs returns the projects in dir, one per subdirectory
+func (tcm *TempCodeManager) listProjectDirs(dir string, scratch bool) ([]TempProjectMetadata, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read temp directory: %w", err)
 	}
 
 	var projects []TempProjectMetadata
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// Hidden entries are endmi's own, like the scratch module
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
-		projectPath := filepath.Join(tempDir, entry.Name())
-		metadata, err := tcm.loadMetadata(projectPath)
+		info, err := entry.Info()
 		if err != nil {
-			// If metadata doesn't exist, create a basic one
-			info, err := entry.Info()
-			if err != nil {
-				continue
-			}
-
-			metadata = &TempProjectMetadata{
-				Name:      entry.Name(),
-				CreatedAt: info.ModTime(),
-				Template:  "unknown",
-				Path:      projectPath,
-			}
+			continue
 		}
-
-		projects = append(projects, *metadata)
+		projects = append(projects, tcm.projectMetadata(filepath.Join(dir, entry.Name()), info, scratch))
 	}
 
 	return projects, nil
 }
 
-// DeleteTempProject removes a temporary project
-func (tcm *TempCodeManager) DeleteTempProject(projectName string) error {
-	tempDir, err := tcm.GetTempDir()
+// DeleteTempProject removes a temporary project. Pinned projects are only
+// removed with force.
+func (tcm *TempCodeManager) DeleteTempProject(projectName string, force bool) error {
+	project, err := tcm.ResolveTempProjectStrict(projectName)
 	if err != nil {
 		return err
 	}
-
-	projectPath := filepath.Join(tempDir, projectName)
-	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-		return fmt.Errorf("temp project '%s' does not exist", projectName)
+	if project.Pinned && !force {
+		return fmt.Errorf("%w: '%s' (unpin it or use --force)", ErrPinned, project.Name)
 	}
 
-	return os.RemoveAll(projectPath)
+	return tcm.removeProject(project)
 }
 
-// CleanAll removes all temporary projects
-func (tcm *TempCodeManager) CleanAll() error {
-	tempDir, err := tcm.GetTempDir()
+// SetPinned pins or unpins a temporary project
+func (tcm *TempCodeManager) SetPinned(projectName string, pinned bool) error {
+	project, err := tcm.ResolveTempProject(projectName)
 	if err != nil {
 		return err
 	}
 
-	entries, err := os.ReadDir(tempDir)
+	project.Pinned = pinned
+	return tcm.saveMetadata(project.Path, project)
+}
+
+// CleanAll removes all temporary projects except pinned ones, which are
+// returned. The scratch module goes too once no pinned snippet needs it.
+func (tcm *TempCodeManager) CleanAll() ([]TempProjectMetadata, error) {
+	projects, err := tcm.ListTempProjects()
 	if err != nil {
-		return fmt.Errorf("failed to read temp directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
+	var kept []TempProjectMetadata
+	scratchNeeded := false
+	for _, p := range projects {
+		if p.Pinned {
+			kept = append(kept, p)
+			scratchNeeded = scratchNeeded || p.Scratch
 			continue
 		}
+		if err := tcm.removeProject(p); err != nil {
+			return kept, fmt.Errorf("failed to remove %s: %w", p.Name, err)
+		}
+	}
 
-		projectPath := filepath.Join(tempDir, entry.Name())
-		if err := os.RemoveAll(projectPath); err != nil {
-			return fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+	if !scratchNeeded {
+		scratchPath, err := tcm.ScratchPath()
+		if err != nil {
+			return kept, err
+		}
+		if err := os.RemoveAll(scratchPath); err != nil {
+			return kept, fmt.Errorf("failed to remove %s: %w", ScratchDir, err)
 		}
 	}
 
-	return nil
+	return kept, nil
 }
 
-// PromoteTempProject moves a temporary project to a permanent location
-func (tcm *TempCodeManager) PromoteTempProject(projectName, targetPath string) error {
-	tempDir, err := tcm.GetTempDir()
+// PromoteOptions controls PromoteTempProject.
+type PromoteOptions struct {
+	// Module, if set, replaces the temp module path (the project name) in
+	// go.mod and in the project's own imports.
+	Module string
+}
+
+// PromoteTempProject moves a temporary project to a permanent location. If
+// the target is on another filesystem, the project is copied and the copy
+// verified before the original is removed.
+func (tcm *TempCodeManager) PromoteTempProject(projectName, targetPath string, opts PromoteOptions) error {
+	project, err := tcm.ResolveTempProjectStrict(projectName)
 	if err != nil {
 		return err
 	}
-
-	sourcePath := filepath.Join(tempDir, projectName)
-	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
-		return fmt.Errorf("temp project '%s' does not exist", projectName)
+	if opts.Module != "" {
+		if err := ValidateModulePath(opts.Module); err != nil {
+			return err
+		}
 	}
 
 	// Check if target already exists
@@ -258,15 +386,29 @@ func (tcm *TempCodeManager) PromoteTempProject(projectName, targetPath string) e
 		return fmt.Errorf("target path '%s' already exists", targetPath)
 	}
 
-	// Move the project
-	if err := os.Rename(sourcePath, targetPath); err != nil {
-		// If rename fails (cross-device), copy and remove
-		return fmt.Errorf("failed to move project: %w (consider using copy instead)", err)
+	if project.Scratch {
+		return tcm.promoteScratchSnippet(project, targetPath, opts.Module)
 	}
 
-	// Remove metadata file from promoted project
+	if err := moveTree(project.Path, targetPath); err != nil {
+		return err
+	}
+	if err := tcm.removeSnapshots(project.Name); err != nil {
+		return fmt.Errorf("promoted, but removing its snapshots failed: %w", err)
+	}
+
+	// Remove temp metadata from promoted project; the generation manifest
+	// under .endmi/ stays with it
 	metaPath := filepath.Join(targetPath, ".endmi_meta.json")
 	os.Remove(metaPath) // Ignore errors
 
+	if opts.Module != "" {
+		unparsed, err := RewriteModulePath(targetPath, opts.Module)
+		if err != nil {
+			return fmt.Errorf("promoted, but renaming the module failed: %w", err)
+		}
+		tcm.App.warnUnparsed(unparsed)
+	}
+
 	return nil
 }
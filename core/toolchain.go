@@ -0,0 +1,260 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/dlcuy22/endmi/extensions"
+)
+
+// ErrGoNotFound is returned when no usable go binary can be located.
+var ErrGoNotFound = errors.New("Go toolchain not found: install Go from https://go.dev/dl/, add it to PATH, or set GoBinary in ~/.endmi/endmi.json (or pass --go <path>)")
+
+// Toolchain describes a Go installation endmi drives for `go mod`, `go get`
+// and friends.
+type Toolchain struct {
+	// Path is the go binary.
+	Path string
+	// Version is the toolchain version as reported by `go env GOVERSION`,
+	// e.g. "go1.22.3".
+	Version string
+	// GOROOT is the toolchain's root directory.
+	GOROOT string
+	// GOTOOLCHAIN is the effective GOTOOLCHAIN setting ("auto", "local", ...).
+	GOTOOLCHAIN string
+}
+
+// ToolchainError explains why the selected toolchain cannot build a template.
+type ToolchainError struct {
+	Toolchain *Toolchain
+	Template  string
+	Required  string
+}
+
+func (e *ToolchainError) Error() string {
+	return fmt.Sprintf(
+		"template '%s' needs Go %s or newer, but %s is %s (GOTOOLCHAIN=%s); upgrade Go, pick a newer SDK with --go, or set GOTOOLCHAIN=auto so go can download one",
+		e.Template, e.Required, e.Toolchain.Path, e.Toolchain.Version, e.Toolchain.GOTOOLCHAIN,
+	)
+}
+
+// FindToolchain locates a Go toolchain. preferred may be a path to a go
+// binary or a Go root, a version such as "1.22" or "go1.22.3" to pick among
+// installed SDKs, or empty. Without a preference, $GOROOT/bin/go is tried
+// before the go found on PATH.
+func FindToolchain(preferred string) (*Toolchain, error) {
+	if preferred != "" {
+		if looksLikeGoVersion(preferred) {
+			return selectToolchainVersion(preferred)
+		}
+		return inspectToolchain(goBinaryIn(preferred))
+	}
+
+	if goroot := os.Getenv("GOROOT"); goroot != "" {
+		if tc, err := inspectToolchain(goBinaryIn(goroot)); err == nil {
+			return tc, nil
+		}
+	}
+
+	path, err := exec.LookPath("go")
+	if err != nil {
+		return nil, ErrGoNotFound
+	}
+	return inspectToolchain(path)
+}
+
+// ListToolchains returns every Go toolchain endmi can find: the one on PATH,
+// $GOROOT, and SDKs installed by golang.org/dl under ~/sdk.
+func ListToolchains() []Toolchain {
+	var candidates []string
+	if path, err := exec.LookPath("go"); err == nil {
+		candidates = append(candidates, path)
+	}
+	if goroot := os.Getenv("GOROOT"); goroot != "" {
+		candidates = append(candidates, goBinaryIn(goroot))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		sdks, _ := filepath.Glob(filepath.Join(home, "sdk", "go*"))
+		for _, sdk := range sdks {
+			candidates = append(candidates, goBinaryIn(sdk))
+		}
+	}
+
+	seen := map[string]bool{}
+	var toolchains []Toolchain
+	for _, candidate := range candidates {
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+
+		tc, err := inspectToolchain(candidate)
+		if err != nil {
+			continue
+		}
+		toolchains = append(toolchains, *tc)
+	}
+
+	return toolchains
+}
+
+// CanSwitch reports whether the go command may download a newer toolchain
+// on demand, as allowed by GOTOOLCHAIN=auto or <name>+auto.
+func (tc *Toolchain) CanSwitch() bool {
+	return tc.GOTOOLCHAIN == "auto" || strings.HasSuffix(tc.GOTOOLCHAIN, "+auto")
+}
+
+// Supports reports whether the toolchain is at least version min.
+func (tc *Toolchain) Supports(min string) bool {
+	return CompareGoVersions(tc.Version, min) >= 0
+}
+
+// Check verifies the toolchain can build the template. It returns a
+// *ToolchainError when the template's minimum Go version is not met and the
+// go command is not allowed to fetch a newer toolchain itself.
+func (tc *Toolchain) Check(t extensions.Template) error {
+	versioner, ok := t.(extensions.MinGoVersioner)
+	if !ok {
+		return nil
+	}
+	required := versioner.MinGoVersion()
+	if required == "" || tc.Supports(required) || tc.CanSwitch() {
+		return nil
+	}
+	return &ToolchainError{Toolchain: tc, Template: t.Name(), Required: required}
+}
+
+// environ returns the environment for commands run with this toolchain. GOROOT
+// is pinned and its bin directory put first on PATH so hooks that call `go`
+// and a stray GOROOT in the user's environment can't mix toolchains.
+func (tc *Toolchain) environ() []string {
+	path := filepath.Join(tc.GOROOT, "bin") + string(os.PathListSeparator) + os.Getenv("PATH")
+	return append(os.Environ(), "GOROOT="+tc.GOROOT, "PATH="+path)
+}
+
+// inspectToolchain runs `go env` on a binary to read its version and settings.
+func inspectToolchain(path string) (*Toolchain, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%w (looked at %s)", ErrGoNotFound, path)
+	}
+
+	cmd := exec.Command(path, "env", "-json", "GOVERSION", "GOROOT", "GOTOOLCHAIN")
+	// Run outside any module so GOTOOLCHAIN=auto can't switch versions on us.
+	cmd.Dir = os.TempDir()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s env: %w", path, err)
+	}
+
+	var env struct {
+		GOVERSION   string
+		GOROOT      string
+		GOTOOLCHAIN string
+	}
+	if err := json.Unmarshal(out, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse %s env output: %w", path, err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	return &Toolchain{
+		Path:        abs,
+		Version:     env.GOVERSION,
+		GOROOT:      env.GOROOT,
+		GOTOOLCHAIN: env.GOTOOLCHAIN,
+	}, nil
+}
+
+// selectToolchainVersion picks the newest installed toolchain matching
+// version, where "1.22" matches any 1.22.x release.
+func selectToolchainVersion(version string) (*Toolchain, error) {
+	want := "go" + strings.TrimPrefix(version, "go")
+
+	var best *Toolchain
+	var installed []string
+	for _, tc := range ListToolchains() {
+		installed = append(installed, tc.Version)
+		if tc.Version != want && !strings.HasPrefix(tc.Version, want+".") {
+			continue
+		}
+		if best == nil || CompareGoVersions(tc.Version, best.Version) > 0 {
+			match := tc
+			best = &match
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no installed Go toolchain matches %s (found: %s); install it with `go install golang.org/dl/%s@latest && %s download`",
+			version, strings.Join(installed, ", "), want, want)
+	}
+	return best, nil
+}
+
+// goBinaryIn accepts a go binary or a Go root and returns the binary path.
+func goBinaryIn(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		bin := "go"
+		if runtime.GOOS == "windows" {
+			bin = "go.exe"
+		}
+		return filepath.Join(path, "bin", bin)
+	}
+	return path
+}
+
+func looksLikeGoVersion(s string) bool {
+	s = strings.TrimPrefix(s, "go")
+	return s != "" && s[0] >= '0' && s[0] <= '9' && !strings.ContainsAny(s, `/\`)
+}
+
+// CompareGoVersions compares two Go versions such as "go1.21", "1.22.3" or
+// "go1.23rc1" and returns -1, 0 or +1. Pre-releases sort before the release.
+func CompareGoVersions(a, b string) int {
+	pa, pb := parseGoVersion(a), parseGoVersion(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseGoVersion returns major, minor, patch and a pre-release rank, where
+// betas rank below rcs and a final release ranks above both.
+func parseGoVersion(v string) [4]int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "go")
+	// Drop suffixes like " X:boringcrypto" or "-devel".
+	if i := strings.IndexAny(v, " -"); i >= 0 {
+		v = v[:i]
+	}
+
+	var out [4]int
+	out[3] = 1 << 30
+	for i, part := range strings.SplitN(v, ".", 3) {
+		digits := part
+		if j := strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }); j >= 0 {
+			digits = part[:j]
+			suffix := part[j:]
+			out[3], _ = strconv.Atoi(strings.TrimLeft(suffix, "abcdefghijklmnopqrstuvwxyz"))
+			if strings.HasPrefix(suffix, "rc") {
+				out[3] += 1000
+			}
+		}
+		out[i], _ = strconv.Atoi(digits)
+	}
+	return out
+}
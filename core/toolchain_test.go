@@ -0,0 +1,33 @@
+package core
+
+import "testing"
+
+func TestCompareGoVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"go1.21", "go1.21", 0},
+		{"go1.21", "1.21", 0},
+		{"go1.21", "go1.21.0", 0},
+		{"go1.21", "go1.22", -1},
+		{"go1.22", "go1.21", 1},
+		{"go1.21.10", "go1.21.9", 1},
+		{"go1.9", "go1.10", -1},
+		{"go2.0", "go1.99", 1},
+		{"go1.23rc1", "go1.23", -1},
+		{"go1.23", "go1.23rc2", 1},
+		{"go1.23rc1", "go1.23rc2", -1},
+		{"go1.23beta1", "go1.23rc1", -1},
+		{"go1.23beta2", "go1.23beta1", 1},
+		{"go1.22.5", "go1.23rc1", -1},
+		{" go1.21.3 ", "go1.21.3", 0},
+		{"go1.21.3 X:boringcrypto", "go1.21.3", 0},
+		{"go1.22-devel", "go1.22", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareGoVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareGoVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
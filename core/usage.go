@@ -0,0 +1,228 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// usageConcurrency caps how many project trees are walked at once.
+const usageConcurrency = 8
+
+// ErrQuotaExceeded is returned by CreateTempProject when the temp workspace
+// is over its quota and TempQuotaMode is "refuse".
+var ErrQuotaExceeded = errors.New("temp workspace quota exceeded")
+
+// TempProjectUsage is a temp project with the disk space it occupies.
+type TempProjectUsage struct {
+	TempProjectMetadata
+	Size  int64 `json:"size_bytes"`
+	Files int   `json:"files"`
+	// Err is set when the project tree could not be fully walked.
+	Err error `json:"-"`
+}
+
+// QuotaStatus compares the temp workspace's size with the configured quota.
+type QuotaStatus struct {
+	// Used is the size of everything in the temp dir.
+	Used  int64
+	Limit int64
+	// Reclaimable lists the expired projects `temp gc` would remove,
+	// largest first.
+	Reclaimable []TempProjectUsage
+}
+
+// Exceeded reports whether the workspace is over its quota.
+func (q *QuotaStatus) Exceeded() bool {
+	return q.Limit > 0 && q.Used > q.Limit
+}
+
+// QuotaError reports an exceeded quota with the projects gc could remove.
+// It unwraps to ErrQuotaExceeded.
+type QuotaError struct {
+	Status *QuotaStatus
+}
+
+func (e *QuotaError) Error() string {
+	msg := fmt.Sprintf("temp workspace uses %s of its %s quota",
+		utils.FormatSize(e.Status.Used), utils.FormatSize(e.Status.Limit))
+	if len(e.Status.Reclaimable) == 0 {
+		return msg + "; no projects have expired, delete some with 'endmi temp delete'"
+	}
+
+	var freed int64
+	names := make([]string, len(e.Status.Reclaimable))
+	for i, p := range e.Status.Reclaimable {
+		freed += p.Size
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("%s; 'endmi temp gc' would free %s by removing %s",
+		msg, utils.FormatSize(freed), strings.Join(names, ", "))
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// DirUsage returns the total size in bytes and number of regular files under
+// dir. Symlinks are counted but not followed. Entries that vanish during the
+// walk, e.g. while `temp gc` runs, are skipped; other errors leave out the
+// entry and the first of them is returned with the rest of the count.
+func DirUsage(dir string) (int64, int, error) {
+	u := &usageCounter{root: dir}
+	if err := filepath.WalkDir(dir, u.visit); err != nil && u.err == nil {
+		u.err = err
+	}
+	return u.size, u.files, u.err
+}
+
+// usageCounter is the filepath.WalkDir callback state of DirUsage.
+type usageCounter struct {
+	root  string
+	size  int64
+	files int
+	err   error
+}
+
+func (u *usageCounter) visit(path string, d fs.DirEntry, err error) error {
+	if err == nil && !d.IsDir() {
+		var info fs.FileInfo
+		if info, err = d.Info(); err == nil {
+			u.size += info.Size()
+			u.files++
+		}
+	}
+	// A missing root is still reported, so callers can tell it apart from
+	// an empty one.
+	if err == nil || errors.Is(err, fs.ErrNotExist) && path != u.root {
+		return nil
+	}
+	if u.err == nil {
+		u.err = err
+	}
+	if d != nil && d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// ProjectUsages measures each project's disk usage concurrently. Results are
+// returned in the order of projects.
+func ProjectUsages(projects []TempProjectMetadata) []TempProjectUsage {
+	usages := make([]TempProjectUsage, len(projects))
+	sem := make(chan struct{}, usageConcurrency)
+	var wg sync.WaitGroup
+
+	for i, p := range projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			size, files, err := DirUsage(p.Path)
+			usages[i] = TempProjectUsage{TempProjectMetadata: p, Size: size, Files: files, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	return usages
+}
+
+// SortUsagesBySize orders usages largest first.
+func SortUsagesBySize(usages []TempProjectUsage) {
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].Size > usages[j].Size
+	})
+}
+
+// Quota measures the temp workspace against the configured TempQuota. A
+// zero Limit means no quota is set.
+func (tcm *TempCodeManager) Quota() (*QuotaStatus, error) {
+	cfg, err := tcm.config()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &QuotaStatus{}
+	if cfg.TempQuota != "" {
+		if status.Limit, err = utils.ParseSize(cfg.TempQuota); err != nil {
+			return nil, fmt.Errorf("invalid TempQuota: %w", err)
+		}
+	}
+
+	// The whole temp dir counts, not just the listed projects: the scratch
+	// module's go.mod and go.sum and anything else endmi keeps there take
+	// space too.
+	tempDir, err := tcm.GetTempDir()
+	if err != nil {
+		return nil, err
+	}
+	if status.Used, _, err = DirUsage(tempDir); err != nil {
+		// Files can vanish mid-walk while another command cleans up; a
+		// partial count is still worth checking against the quota.
+		tcm.App.emit(Event{Kind: EventWarning, Line: fmt.Sprintf("temp workspace size is incomplete: %v", err)})
+	}
+
+	expired, err := tcm.ExpiredProjects(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	status.Reclaimable = ProjectUsages(expired)
+	SortUsagesBySize(status.Reclaimable)
+
+	return status, nil
+}
+
+// SnapshotsUsage returns the disk space taken by the snapshots of all temp
+// projects.
+func (tcm *TempCodeManager) SnapshotsUsage() (int64, error) {
+	root, err := tcm.snapshotsPath()
+	if err != nil {
+		return 0, err
+	}
+	size, _, err := DirUsage(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// checkQuota enforces TempQuota before a temp project is created: it warns
+// through the event stream, or fails with a QuotaError when TempQuotaMode
+// is "refuse".
+func (tcm *TempCodeManager) checkQuota() error {
+	cfg, err := tcm.config()
+	if err != nil {
+		return err
+	}
+	if cfg.TempQuota == "" {
+		return nil
+	}
+
+	status, err := tcm.Quota()
+	if err != nil {
+		return err
+	}
+	if !status.Exceeded() {
+		return nil
+	}
+
+	quotaErr := &QuotaError{Status: status}
+	switch cfg.TempQuotaMode {
+	case "", "warn":
+		tcm.App.emit(Event{Kind: EventWarning, Line: quotaErr.Error()})
+		return nil
+	case "refuse":
+		return quotaErr
+	default:
+		return fmt.Errorf("invalid TempQuotaMode %q (use warn or refuse)", cfg.TempQuotaMode)
+	}
+}
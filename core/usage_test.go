@@ -0,0 +1,106 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirUsage(t *testing.T) {
+	dir := t.TempDir()
+	for rel, size := range map[string]int{"a.txt": 10, "sub/b.txt": 20, "sub/deep/c.txt": 30} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A dangling symlink is counted as the link itself.
+	if err := os.Symlink("missing", filepath.Join(dir, "sub", "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	size, files, err := DirUsage(dir)
+	if err != nil {
+		t.Fatalf("DirUsage: %v", err)
+	}
+	if want := int64(60 + len("missing")); size != want || files != 4 {
+		t.Errorf("DirUsage = %d bytes in %d files, want %d in 4", size, files, want)
+	}
+
+	if _, _, err := DirUsage(filepath.Join(dir, "nope")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("DirUsage of a missing dir = %v, want ErrNotExist", err)
+	}
+}
+
+// fakeEntry is a file whose Info fails with err.
+type fakeEntry struct {
+	name string
+	dir  bool
+	err  error
+}
+
+func (e fakeEntry) Name() string               { return e.name }
+func (e fakeEntry) IsDir() bool                { return e.dir }
+func (e fakeEntry) Type() fs.FileMode          { return 0 }
+func (e fakeEntry) Info() (fs.FileInfo, error) { return fakeInfo{e.name}, e.err }
+
+type fakeInfo struct{ name string }
+
+func (i fakeInfo) Name() string       { return i.name }
+func (i fakeInfo) Size() int64        { return 5 }
+func (i fakeInfo) Mode() fs.FileMode  { return 0644 }
+func (i fakeInfo) ModTime() time.Time { return time.Time{} }
+func (i fakeInfo) IsDir() bool        { return false }
+func (i fakeInfo) Sys() any           { return nil }
+
+func TestUsageCounterKeepsCountingPastErrors(t *testing.T) {
+	denied := &fs.PathError{Op: "open", Path: "/ws/locked", Err: fs.ErrPermission}
+	vanished := &fs.PathError{Op: "lstat", Path: "/ws/gone", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name      string
+		path      string
+		entry     fs.DirEntry
+		walkErr   error
+		wantErr   error
+		wantSkip  bool
+		wantFiles int
+	}{
+		{name: "file", path: "/ws/a", entry: fakeEntry{name: "a"}, wantFiles: 1},
+		{name: "file removed before Info", path: "/ws/gone", entry: fakeEntry{name: "gone", err: vanished}},
+		{name: "directory removed before reading", path: "/ws/gone", entry: fakeEntry{name: "gone", dir: true}, walkErr: vanished},
+		{name: "unreadable directory", path: "/ws/locked", entry: fakeEntry{name: "locked", dir: true}, walkErr: denied, wantErr: denied, wantSkip: true},
+		{name: "file Info fails", path: "/ws/locked", entry: fakeEntry{name: "locked", err: denied}, wantErr: denied},
+		{name: "missing root", path: "/ws", walkErr: vanished, wantErr: vanished},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &usageCounter{root: "/ws"}
+			ret := u.visit(tt.path, tt.entry, tt.walkErr)
+			if (ret == filepath.SkipDir) != tt.wantSkip || ret != nil && ret != filepath.SkipDir {
+				t.Errorf("visit returned %v, want SkipDir %v", ret, tt.wantSkip)
+			}
+			if u.err != tt.wantErr {
+				t.Errorf("recorded error %v, want %v", u.err, tt.wantErr)
+			}
+			if u.files != tt.wantFiles {
+				t.Errorf("counted %d files, want %d", u.files, tt.wantFiles)
+			}
+
+			// Counting goes on after an error and the first one is kept.
+			if u.visit("/ws/b", fakeEntry{name: "b"}, nil) != nil || u.files != tt.wantFiles+1 {
+				t.Errorf("visit stopped counting after %v", tt.walkErr)
+			}
+			u.visit("/ws/other", fakeEntry{name: "other", err: errors.New("later")}, nil)
+			if tt.wantErr != nil && u.err != tt.wantErr {
+				t.Errorf("first error replaced by %v", u.err)
+			}
+		})
+	}
+}
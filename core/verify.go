@@ -0,0 +1,101 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VerifyLogFile is where failed verification output is written, relative to
+// the project root.
+const VerifyLogFile = ".endmi/verify.log"
+
+// ErrVerifyFailed is reported by the verify step when any check fails. The
+// step is optional, so the project is kept and the failure recorded in the
+// Report.
+var ErrVerifyFailed = errors.New("project verification failed")
+
+// VerifyResult is the outcome of one post-create check.
+type VerifyResult struct {
+	Name     string
+	Command  []string
+	Passed   bool
+	Output   []string
+	Duration time.Duration
+}
+
+// Verified reports whether every check passed. It is true when no checks ran.
+func (r *Report) Verified() bool {
+	for _, result := range r.Verification {
+		if !result.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+var verifyChecks = []struct {
+	name string
+	args []string
+}{
+	{"build", []string{"build", "./..."}},
+	{"vet", []string{"vet", "./..."}},
+	{"test", []string{"test", "./..."}},
+}
+
+// verify builds, vets and tests the project. Every check runs even if an
+// earlier one fails, so the summary is complete.
+func verify(ctx *BuildContext) error {
+	report := ctx.Report
+	for i, check := range verifyChecks {
+		start := time.Now()
+		output, err := ctx.App.captureCommand(PhaseVerify, i+1, len(verifyChecks), ctx.Path, "go", check.args...)
+		report.Verification = append(report.Verification, VerifyResult{
+			Name:     check.name,
+			Command:  append([]string{"go"}, check.args...),
+			Passed:   err == nil,
+			Output:   output,
+			Duration: time.Since(start),
+		})
+	}
+
+	if report.Verified() {
+		return nil
+	}
+
+	logPath, err := writeVerifyLog(ctx.Path, report.Verification)
+	if err != nil {
+		return fmt.Errorf("%w (and failed to write log: %v)", ErrVerifyFailed, err)
+	}
+	report.VerifyLog = logPath
+	return fmt.Errorf("%w, see %s", ErrVerifyFailed, logPath)
+}
+
+// writeVerifyLog saves the output of the failed checks and returns the log
+// path.
+func writeVerifyLog(projectPath string, results []VerifyResult) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "endmi verification, %s\n", time.Now().Format(time.RFC3339))
+	for _, result := range results {
+		if result.Passed {
+			continue
+		}
+		fmt.Fprintf(&b, "\n$ %s\n", strings.Join(result.Command, " "))
+		for _, line := range result.Output {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	logPath := filepath.Join(projectPath, VerifyLogFile)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(logPath, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+	return logPath, nil
+}
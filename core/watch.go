@@ -0,0 +1,255 @@
+package core
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = 500 * time.Millisecond
+	defaultWatchDebounce = 300 * time.Millisecond
+	// watchWaitDelay bounds how long a killed run may hold its output
+	// pipes open through processes it left behind.
+	watchWaitDelay = 2 * time.Second
+)
+
+// WatchOptions controls WatchTempProject.
+type WatchOptions struct {
+	// Test runs `go test ./...` instead of the program.
+	Test bool
+	// Args are passed to the program; ignored with Test.
+	Args []string
+	// Interval is how often the project is polled for changes.
+	Interval time.Duration
+	// Debounce is how long the files must stay unchanged before a re-run,
+	// so a burst of saves causes a single run.
+	Debounce time.Duration
+	Stdout   io.Writer
+	Stderr   io.Writer
+	// OnRun is called before each run with the files that changed, relative
+	// to the project; it is nil for the first run.
+	OnRun func(changed []string)
+	// OnExit is called when a run ends on its own, with its exit code or
+	// ErrBuildFailed. Runs interrupted by a change are not reported.
+	OnExit func(code int, err error)
+}
+
+// fileStamp is what a poll compares to detect a change.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchTempProject runs a temp project, then polls its .go files and go.mod
+// and re-runs it after every change, stopping the previous run first. An
+// empty name watches the most recently used project. It returns when ctx is
+// cancelled.
+func (tcm *TempCodeManager) WatchTempProject(ctx context.Context, name string, opts WatchOptions) error {
+	if name == "" {
+		name = "@last"
+	}
+	project, err := tcm.ResolveTempProject(name)
+	if err != nil {
+		return err
+	}
+	if err := tcm.touch(project); err != nil {
+		tcm.App.emit(Event{Kind: EventWarning, Line: "failed to record last use: " + err.Error()})
+	}
+
+	app, err := tcm.App.withDefaultToolchain()
+	if err != nil {
+		return err
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = defaultWatchDebounce
+	}
+
+	binDir, err := os.MkdirTemp("", "endmi-watch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	stamps, err := scanWatched(project.Path)
+	if err != nil {
+		return err
+	}
+
+	var current *watchRun
+	start := func(changed []string) {
+		if opts.OnRun != nil {
+			opts.OnRun(changed)
+		}
+		current = app.startWatchRun(ctx, project.Path, binDir, opts)
+	}
+	start(nil)
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var pending []string
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			current.stop()
+			return nil
+
+		case <-ticker.C:
+			next, err := scanWatched(project.Path)
+			if err != nil {
+				// Files can vanish mid-scan while an editor saves; retry
+				// on the next tick.
+				continue
+			}
+			if changed := diffStamps(stamps, next); len(changed) > 0 {
+				pending = mergeNames(pending, changed)
+				lastChange = time.Now()
+			}
+			stamps = next
+
+			if len(pending) > 0 && time.Since(lastChange) >= opts.Debounce {
+				current.stop()
+				start(pending)
+				pending = nil
+			}
+		}
+	}
+}
+
+// watchRun is one build-and-run (or test) cycle of a watched project.
+type watchRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+// startWatchRun builds and runs the project, or tests it, in the background.
+func (a App) startWatchRun(ctx context.Context, dir, binDir string, opts WatchOptions) *watchRun {
+	runCtx, cancel := context.WithCancel(ctx)
+	run := &watchRun{cancel: cancel, done: make(chan struct{})}
+	a.Context = runCtx
+
+	go func() {
+		defer close(run.done)
+
+		var code int
+		var err error
+		if opts.Test {
+			cmd := a.command(dir, "go", "test", "./...")
+			cmd.Stdout = opts.Stdout
+			cmd.Stderr = opts.Stderr
+			// Killing only the go command would leave its test binaries
+			// running into the next run.
+			killGroupOnCancel(cmd)
+			cmd.WaitDelay = watchWaitDelay
+			code, err = exitCode(cmd.Run())
+		} else {
+			var bin string
+			bin, code, err = a.buildProgram(dir, binDir, opts.Stdout, opts.Stderr)
+			if err == nil {
+				cmd := a.command(dir, bin, opts.Args...)
+				cmd.Stdout = opts.Stdout
+				cmd.Stderr = opts.Stderr
+				code, err = exitCode(cmd.Run())
+			}
+		}
+
+		run.mu.Lock()
+		stopped := run.stopped
+		run.mu.Unlock()
+		if !stopped && runCtx.Err() == nil && opts.OnExit != nil {
+			opts.OnExit(code, err)
+		}
+	}()
+
+	return run
+}
+
+// stop kills the run if it is still going and waits for it to exit.
+func (r *watchRun) stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.cancel()
+	<-r.done
+}
+
+// scanWatched stamps every .go file and go.mod under dir, skipping vendor,
+// testdata and hidden directories such as .endmi.
+func scanWatched(dir string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != dir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") && name != "go.mod" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		stamps[filepath.ToSlash(rel)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps, err
+}
+
+// diffStamps returns the files added, removed or modified between two
+// scans, sorted.
+func diffStamps(before, after map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		old, ok := before[path]
+		if !ok || old.size != stamp.size || !old.modTime.Equal(stamp.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// mergeNames adds names missing from list, keeping it sorted.
+func mergeNames(list, names []string) []string {
+	for _, name := range names {
+		i := sort.SearchStrings(list, name)
+		if i < len(list) && list[i] == name {
+			continue
+		}
+		list = append(list, "")
+		copy(list[i+1:], list[i:])
+		list[i] = name
+	}
+	return list
+}
@@ -6,9 +6,10 @@ func init() {
 
 type ebitenTemplate struct{}
 
-func (ebitenTemplate) Name() string        { return "ebiten" }
-func (ebitenTemplate) Description() string { return "Ebiten game engine template" }
-func (ebitenTemplate) RootDir() string     { return "" }
+func (ebitenTemplate) Name() string         { return "ebiten" }
+func (ebitenTemplate) Description() string  { return "Ebiten game engine template" }
+func (ebitenTemplate) RootDir() string      { return "" }
+func (ebitenTemplate) MinGoVersion() string { return "1.22" }
 func (ebitenTemplate) Dependencies() []string {
 	return []string{"github.com/hajimehoshi/ebiten/v2"}
 }
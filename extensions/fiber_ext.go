@@ -6,9 +6,10 @@ func init() {
 
 type fiberTemplate struct{}
 
-func (fiberTemplate) Name() string        { return "fiber" }
-func (fiberTemplate) Description() string { return "fiber template" }
-func (fiberTemplate) RootDir() string     { return "" }
+func (fiberTemplate) Name() string         { return "fiber" }
+func (fiberTemplate) Description() string  { return "fiber template" }
+func (fiberTemplate) RootDir() string      { return "" }
+func (fiberTemplate) MinGoVersion() string { return "1.20" }
 func (fiberTemplate) Dependencies() []string {
 	return []string{"github.com/gofiber/fiber/v2", "github.com/gofiber/template/html/v2"}
 }
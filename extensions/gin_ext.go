@@ -6,9 +6,10 @@ func init() {
 
 type ginTemplate struct{}
 
-func (ginTemplate) Name() string        { return "gin" }
-func (ginTemplate) Description() string { return "Gin Web Framework" }
-func (ginTemplate) RootDir() string     { return "" }
+func (ginTemplate) Name() string         { return "gin" }
+func (ginTemplate) Description() string  { return "Gin Web Framework" }
+func (ginTemplate) RootDir() string      { return "" }
+func (ginTemplate) MinGoVersion() string { return "1.25" }
 func (ginTemplate) Dependencies() []string {
 	return []string{"github.com/gin-gonic/gin"}
 }
@@ -33,3 +33,42 @@ func RegisterTemplate(t Template) {
 func BuiltinTemplates() []Template {
 	return registry
 }
+
+// Hook is a command run inside the project directory after the files and
+// dependencies are in place. Command[0] is the program, the rest its args.
+type Hook struct {
+	Name    string
+	Command []string
+}
+
+// HookProvider is an optional interface for templates that need extra
+// commands (code generation, asset builds, ...) run after scaffolding.
+type HookProvider interface {
+	Hooks() []Hook
+}
+
+// MinGoVersioner is an optional interface for templates whose dependencies
+// need a minimum Go version. Return a version such as "1.22".
+type MinGoVersioner interface {
+	MinGoVersion() string
+}
+
+// Versioner is an optional interface for templates that track their own
+// version. Templates without it are recorded with the endmi version.
+type Versioner interface {
+	Version() string
+}
+
+// ParamTemplate is an optional interface for templates that accept extra
+// user parameters (e.g. a listen port). When implemented, FilesWithParams is
+// used instead of Files.
+type ParamTemplate interface {
+	FilesWithParams(projectName string, params map[string]string) map[string]string
+}
+
+// VendorRecommender is an optional interface for templates that should be
+// vendored by default, e.g. for targets that build without network access.
+// Users can still opt out with --no-vendor.
+type VendorRecommender interface {
+	RecommendVendor() bool
+}
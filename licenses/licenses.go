@@ -0,0 +1,83 @@
+// Package licenses renders LICENSE files and SPDX file headers. It is shared
+// by every template, so templates never ship their own license text.
+package licenses
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//go:embed texts/*.txt
+var texts embed.FS
+
+// License is a license endmi can write into a new project.
+type License struct {
+	// ID is the SPDX identifier, or "proprietary".
+	ID string
+	// Name is the human-friendly name shown in the UI.
+	Name string
+}
+
+var builtin = []License{
+	{ID: "MIT", Name: "MIT License"},
+	{ID: "Apache-2.0", Name: "Apache License 2.0"},
+	{ID: "BSD-3-Clause", Name: "BSD 3-Clause License"},
+	{ID: "MPL-2.0", Name: "Mozilla Public License 2.0"},
+	{ID: "proprietary", Name: "Proprietary (all rights reserved)"},
+}
+
+// All returns the supported licenses.
+func All() []License {
+	return builtin
+}
+
+// Find looks a license up by ID, ignoring case.
+func Find(id string) (License, error) {
+	for _, l := range builtin {
+		if strings.EqualFold(l.ID, id) {
+			return l, nil
+		}
+	}
+
+	ids := make([]string, len(builtin))
+	for i, l := range builtin {
+		ids[i] = l.ID
+	}
+	return License{}, fmt.Errorf("license '%s' not found (available: %s)", id, strings.Join(ids, ", "))
+}
+
+// SPDX returns the identifier used in SPDX-License-Identifier lines.
+func (l License) SPDX() string {
+	if l.ID == "proprietary" {
+		return "LicenseRef-Proprietary"
+	}
+	return l.ID
+}
+
+// Render returns the full LICENSE text for the given author and year.
+func (l License) Render(author string, year int) (string, error) {
+	raw, err := texts.ReadFile("texts/" + l.ID + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("no text for license '%s': %w", l.ID, err)
+	}
+
+	tmpl, err := template.New(l.ID).Parse(string(raw))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	err = tmpl.Execute(&b, struct {
+		Author string
+		Year   int
+	}{author, year})
+	return b.String(), err
+}
+
+// Header returns the SPDX comment block to put at the top of a Go file,
+// followed by a blank line so it never becomes the package doc comment.
+func (l License) Header(author string, year int) string {
+	return fmt.Sprintf("// Copyright (c) %d %s\n// SPDX-License-Identifier: %s\n\n", year, author, l.SPDX())
+}
@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"log"
 
 	"github.com/dlcuy22/endmi/core"
 	"github.com/dlcuy22/endmi/extensions"
+	"github.com/dlcuy22/endmi/licenses"
 	"github.com/dlcuy22/endmi/ui"
 	"github.com/dlcuy22/endmi/utils"
 )
@@ -20,22 +32,59 @@ func showHelp() {
 	fmt.Println("Usage:")
 	fmt.Println("  endmi create [project-name] [flags]    Create a new Go project")
 	fmt.Println("  endmi temp <command> [flags]           Manage temporary code workspace")
+	fmt.Println("  endmi apply <stack.json> [flags]       Create every project listed in a stack file")
+	fmt.Println("  endmi info [path]                      Show how a project was generated and what changed")
+	fmt.Println("  endmi template list                    List available templates")
+	fmt.Println("  endmi toolchains                       List installed Go toolchains")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("  -t, --template <name>                  Specify template (skip interactive selection)")
 	fmt.Println("  -n, --name <name>                      Specify project name (for temp create)")
+	fmt.Println("      --go <path|version>                Go binary, Go root or installed version to use")
+	fmt.Println("      --keep                             Keep the project created for a snippet (temp run)")
+	fmt.Println("      --scratch, --no-scratch            Run snippets in the shared scratch module (default from config)")
+	fmt.Println("      --ttl <duration>                   Expire a temp project after e.g. 12h, 3d, 2w (default from config)")
+	fmt.Println("      --tag <tag>, --note <text>         Tag and describe a temp project (temp create, repeat --tag)")
+	fmt.Println("      --since <duration>                 Only list temp projects created within e.g. 7d (temp list)")
+	fmt.Println("  -o, --output <json|table|names>        Machine-readable output (temp list, template list, info)")
+	fmt.Println("      --verify, --no-verify              Build, vet and test the new project (default from config)")
+	fmt.Println("      --vendor, --no-vendor              Run 'go mod vendor' after tidy (default from config/template)")
+	fmt.Println("  -l, --license <id>                     Write a LICENSE (MIT, Apache-2.0, BSD-3-Clause, MPL-2.0, proprietary, none)")
+	fmt.Println("      --license-headers                  Add SPDX license headers to generated .go files")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  endmi create                           Start interactive project creation")
 	fmt.Println("  endmi create my-api                    Create project named 'my-api'")
 	fmt.Println("  endmi create my-api -t fiber           Create 'my-api' with fiber template")
+	fmt.Println("  endmi create my-api --go 1.22          Create 'my-api' using the Go 1.22 SDK")
+	fmt.Println("  endmi create my-api -t gin -l MIT      Create 'my-api' with an MIT LICENSE")
+	fmt.Println("  endmi apply stack.json                 Create the API, worker and CLI listed in stack.json")
 	fmt.Println("  endmi temp create                      Create a new temporary project")
 	fmt.Println("  endmi temp create -t gin               Create temp project with gin template")
 	fmt.Println("  endmi temp create -t blank -n mytest   Create named temp project")
+	fmt.Println("  endmi temp create -t blank --ttl 3d    Create temp project that expires in 3 days")
+	fmt.Println("  endmi temp create -t blank --tag http --note 'testing retry lib'  Create a tagged temp project")
 	fmt.Println("  endmi temp list                        List all temporary projects")
+	fmt.Println("  endmi temp list --sort size            List temporary projects, largest first")
+	fmt.Println("  endmi temp list --tag http --since 7d  List this week's projects tagged http")
+	fmt.Println("  endmi temp list -o names | fzf         Pick a temporary project with fzf")
+	fmt.Println("  endmi temp run mytest -- -v            Run 'mytest' with arguments")
+	fmt.Println("  echo 'fmt.Println(1<<10)' | endmi temp run -   Run a snippet from stdin")
+	fmt.Println("  endmi temp watch mytest --test         Re-run 'mytest' tests on every change")
 	fmt.Println("  endmi temp delete <name>               Delete a temporary project")
-	fmt.Println("  endmi temp clean                       Remove all temporary projects")
+	fmt.Println("  endmi temp delete temp_17              Delete the one project whose name starts with temp_17")
+	fmt.Println("  endmi temp run @2                      Run the second most recently used project")
+	fmt.Println("  endmi temp clone mytest mytest-v2      Try a variant of 'mytest' side by side")
+	fmt.Println("  endmi temp snapshot mytest -m 'works'  Save a snapshot of 'mytest'")
+	fmt.Println("  endmi temp restore mytest 1            Roll 'mytest' back to snapshot 1")
+	fmt.Println("  endmi temp pin mytest                  Keep 'mytest' through clean and gc")
+	fmt.Println("  endmi temp clean                       Remove all unpinned temporary projects")
+	fmt.Println("  endmi temp gc --dry-run                Show temporary projects past their TTL")
 	fmt.Println("  endmi temp promote <name> <path>       Move temp project to permanent location")
+	fmt.Println("  endmi temp promote mytest ./api --module github.com/me/api")
+	fmt.Println("                                         Promote and rename the module")
+	fmt.Println("  endmi temp promote mytest --into ../svc/internal/foo")
+	fmt.Println("                                         Promote into a package of an existing module")
 	fmt.Println()
 	fmt.Println("Available templates:")
 	for _, t := range extensions.BuiltinTemplates() {
@@ -43,6 +92,184 @@ func showHelp() {
 	}
 }
 
+// resolveToolchain locates the Go toolchain from the --go flag or the
+// GoBinary config entry, exiting with an actionable message on failure.
+func resolveToolchain(goFlag string, cfg *utils.Config) *core.Toolchain {
+	preferred := goFlag
+	if preferred == "" {
+		preferred = cfg.GoBinary
+	}
+
+	toolchain, err := core.FindToolchain(preferred)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return toolchain
+}
+
+// exitOnInvalidName validates a project name before any work is done and
+// exits with a suggested alternative when it is unusable
+func exitOnInvalidName(name string) {
+	err := core.ValidateProjectName(name)
+	if err == nil {
+		return
+	}
+
+	fmt.Printf("Error: %v\n", err)
+	var nameErr *core.NameError
+	if errors.As(err, &nameErr) {
+		fmt.Println()
+		fmt.Println("Project names must be lowercase ASCII letters, digits, '-', '_' or '.',")
+		fmt.Println("and usable as a Go module path.")
+	}
+	os.Exit(1)
+}
+
+// resolveTempName expands a temp project reference (a name, unique prefix,
+// fuzzy match, @last or @N) to the project's full name using resolve, one of
+// tcm.ResolveTempProject or tcm.ResolveTempProjectStrict. An ambiguous match
+// opens a picker on a terminal and is an error listing the candidates
+// otherwise.
+func resolveTempName(resolve func(string) (core.TempProjectMetadata, error), name string) string {
+	project, err := resolve(name)
+	var ambiguous *core.AmbiguousNameError
+	if errors.As(err, &ambiguous) && utils.IsInteractive() {
+		project, err = ui.PickTempProject(ambiguous.Name, ambiguous.Candidates)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return project.Name
+}
+
+// outputFlag removes --output/-o <format> from args, stopping at "--" so
+// program arguments are left alone, and returns the requested format
+func outputFlag(args []string) (ui.OutputFormat, []string, error) {
+	output := ui.OutputText
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		value, isFlag := strings.CutPrefix(arg, "--output=")
+		if !isFlag && (arg == "--output" || arg == "-o") {
+			if i+1 >= len(args) {
+				return output, args, fmt.Errorf("%w: %s requires a format (json, table or names)", ui.ErrUsage, arg)
+			}
+			value, isFlag = args[i+1], true
+			i++
+		}
+		if !isFlag {
+			rest = append(rest, arg)
+			continue
+		}
+
+		var err error
+		if output, err = ui.ParseOutputFormat(value); err != nil {
+			return output, args, err
+		}
+	}
+	return output, rest, nil
+}
+
+// parseOutput removes --output from os.Args and returns the format; only
+// the list-style commands call it, so other commands keep -o as an argument
+func parseOutput() ui.OutputFormat {
+	output, args, err := outputFlag(os.Args)
+	if err != nil {
+		exitWithError(output, err)
+	}
+	os.Args = args
+	return output
+}
+
+// exitWithError prints err in the requested output format and exits with
+// status 1
+func exitWithError(output ui.OutputFormat, err error) {
+	if output == ui.OutputJSON {
+		ui.WriteErrorJSON(os.Stdout, err)
+	} else {
+		fmt.Printf("Error: %v\n", err)
+	}
+	os.Exit(1)
+}
+
+// resolveAuthor returns the configured author, falling back to git's
+// user.name and then the OS user name
+func resolveAuthor(cfg *utils.Config) string {
+	if cfg.Author != "" {
+		return cfg.Author
+	}
+	if out, err := exec.Command("git", "config", "user.name").Output(); err == nil {
+		if name := strings.TrimSpace(string(out)); name != "" {
+			return name
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "the authors"
+}
+
+// isSnippet reports whether a `temp run` target is Go source rather than a
+// temp project name: "-" for stdin, or an existing .go file
+func isSnippet(target string) bool {
+	if target == "-" {
+		return true
+	}
+	if !strings.HasSuffix(target, ".go") {
+		return false
+	}
+	info, err := os.Stat(target)
+	return err == nil && !info.IsDir()
+}
+
+// printTempSubcommands lists the `endmi temp` subcommands
+func printTempSubcommands() {
+	fmt.Println("Available subcommands:")
+	fmt.Println("  create                Create a new temporary project")
+	fmt.Println("  list [--sort size]    List temporary projects with their disk usage")
+	fmt.Println("  list --tag http --template gin --since 7d  Only list matching projects")
+	fmt.Println("  tag <name> [tag...]   Add tags to a temp project (--remove <tag> to drop one)")
+	fmt.Println("  note <name> [text]    Show or set a temp project's note (--clear to remove)")
+	fmt.Println("  delete <name>         Delete a temporary project (--force for pinned ones)")
+	fmt.Println("  clone <name> [new]    Copy a temp project into a new one with its own module")
+	fmt.Println("  snapshot <name> [-m msg]  Save a point-in-time copy of a temp project")
+	fmt.Println("  snapshots <name>      List a temp project's snapshots")
+	fmt.Println("  restore <name> <id>   Roll a temp project back to a snapshot")
+	fmt.Println("  pin <name>            Protect a temp project from clean, gc and quota cleanup")
+	fmt.Println("  unpin <name>          Remove the protection again")
+	fmt.Println("  clean                 Remove all unpinned temporary projects")
+	fmt.Println("  run [name] [-- args]  Build and run a temp project (default: most recently used)")
+	fmt.Println("  run <file.go | ->     Run a Go snippet from a file or stdin in a throwaway project")
+	fmt.Println("  watch [name] [--test] Re-run a temp project whenever its files change")
+	fmt.Println("  gc [--dry-run]        Remove expired temporary projects")
+	fmt.Println("  promote <name> <path> Move temp project to permanent location")
+	fmt.Println("  promote <name> --into <dir>  Turn a temp project into a package of an existing module")
+	fmt.Println()
+	fmt.Println("<name> may be a unique prefix or fuzzy match of a project name, or @last, @1, @2, ...")
+	fmt.Println("for the most recently used projects. delete, restore and promote accept only exact names,")
+	fmt.Println("unique prefixes and @last/@N, never fuzzy matches.")
+}
+
+// printReport prints timings and verification results after a CLI creation
+// and exits with status 1 if verification failed
+func printReport(report *core.Report) {
+	fmt.Print(ui.RenderTimings(report.Timings, report.Total))
+	if v := ui.RenderVerification(report); v != "" {
+		fmt.Println()
+		fmt.Print(v)
+	}
+	if !report.Verified() {
+		os.Exit(1)
+	}
+}
+
 func main() {
 	exists, err := utils.CheckConfigExists()
 	if err != nil {
@@ -61,6 +288,21 @@ func main() {
 		log.Println("config already exists, skipping creation")
 	}
 
+	cfg, err := utils.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	if cfg.TempAutoGC {
+		tcm := &core.TempCodeManager{App: &core.App{}, Config: cfg}
+		removed, err := tcm.GC(false)
+		if err != nil {
+			log.Printf("temp gc failed: %v", err)
+		} else if len(removed) > 0 {
+			log.Printf("temp gc removed %d expired project(s)", len(removed))
+		}
+	}
+
 	if len(os.Args) < 2 {
 		showHelp()
 		return
@@ -72,6 +314,12 @@ func main() {
 	case "create":
 		var projectName string
 		var templateName string
+		var goFlag string
+		verify := cfg.Verify
+		vendor, noVendor := cfg.Vendor, false
+		licenseID := cfg.License
+		licenseHeaders := cfg.LicenseHeaders
+		licenseFlag := false
 
 		// Parse arguments and flags
 		for i := 2; i < len(os.Args); i++ {
@@ -84,12 +332,58 @@ func main() {
 					fmt.Println("Error: --template/-t requires a template name")
 					os.Exit(1)
 				}
+			} else if arg == "--go" {
+				if i+1 < len(os.Args) {
+					goFlag = os.Args[i+1]
+					i++
+				} else {
+					fmt.Println("Error: --go requires a path or version")
+					os.Exit(1)
+				}
+			} else if arg == "--verify" {
+				verify = true
+			} else if arg == "--no-verify" {
+				verify = false
+			} else if arg == "--vendor" {
+				vendor, noVendor = true, false
+			} else if arg == "--no-vendor" {
+				vendor, noVendor = false, true
+			} else if arg == "--license" || arg == "-l" {
+				if i+1 < len(os.Args) {
+					licenseID = os.Args[i+1]
+					licenseFlag = true
+					i++
+				} else {
+					fmt.Println("Error: --license/-l requires a license ID")
+					os.Exit(1)
+				}
+			} else if arg == "--license-headers" {
+				licenseHeaders = true
 			} else if projectName == "" {
 				projectName = arg
 			}
 		}
 
-		app := &core.App{}
+		if projectName != "" {
+			exitOnInvalidName(projectName)
+		}
+
+		app := &core.App{
+			Toolchain:      resolveToolchain(goFlag, cfg),
+			Verify:         verify,
+			Vendor:         vendor,
+			NoVendor:       noVendor,
+			Author:         resolveAuthor(cfg),
+			LicenseHeaders: licenseHeaders,
+		}
+		if licenseID != "" && licenseID != "none" {
+			l, err := licenses.Find(licenseID)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			app.License = &l
+		}
 		templates := extensions.BuiltinTemplates()
 
 		// If template is specified via flag, create project directly
@@ -109,16 +403,19 @@ func main() {
 			}
 
 			// Create project directly
+			app.Events = ui.NewProgressPrinter(os.Stdout).Handle
 			fmt.Printf("Creating project '%s' with template '%s'...\n", projectName, templateName)
-			if err := app.CreateProject(selectedTemplate, projectName); err != nil {
+			report, err := app.CreateProject(selectedTemplate, projectName)
+			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 			fmt.Printf("\n✅ Project '%s' created successfully!\n", projectName)
-			fmt.Printf("   cd %s && go run .\n", projectName)
+			fmt.Printf("   cd %s && go run .\n\n", projectName)
+			printReport(report)
 		} else {
 			// Use interactive UI
-			program := ui.NewProgram(app, templates, projectName)
+			program := ui.NewProgram(app, templates, projectName, !licenseFlag)
 			if _, err := program.Run(); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
@@ -128,22 +425,229 @@ func main() {
 		showHelp()
 		os.Exit(0)
 
+	case "apply":
+		if len(os.Args) < 3 {
+			fmt.Println("Error: apply requires a stack file")
+			fmt.Println("Usage: endmi apply <stack.json> [--go <path|version>] [-j <n>]")
+			os.Exit(1)
+		}
+
+		stackPath := os.Args[2]
+		var goFlag string
+		concurrency := 0
+		for i := 3; i < len(os.Args); i++ {
+			arg := os.Args[i]
+			if arg == "--go" {
+				if i+1 < len(os.Args) {
+					goFlag = os.Args[i+1]
+					i++
+				} else {
+					fmt.Println("Error: --go requires a path or version")
+					os.Exit(1)
+				}
+			} else if arg == "-j" || arg == "--jobs" {
+				// A missing value leaves n at 0.
+				var n int
+				var err error
+				if i+1 < len(os.Args) {
+					n, err = strconv.Atoi(os.Args[i+1])
+					i++
+				}
+				if err != nil || n < 1 {
+					fmt.Println("Error: -j/--jobs requires a positive number")
+					fmt.Println("Usage: endmi apply <stack.json> [--go <path|version>] [-j <n>]")
+					os.Exit(1)
+				}
+				concurrency = n
+			} else {
+				fmt.Printf("Error: unexpected argument '%s'\n", arg)
+				fmt.Println("Usage: endmi apply <stack.json> [--go <path|version>] [-j <n>]")
+				os.Exit(1)
+			}
+		}
+
+		stack, err := core.LoadStack(stackPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if concurrency > 0 {
+			stack.Concurrency = concurrency
+		}
+
+		app := &core.App{
+			Toolchain:      resolveToolchain(goFlag, cfg),
+			Verify:         cfg.Verify,
+			Vendor:         cfg.Vendor,
+			Author:         resolveAuthor(cfg),
+			LicenseHeaders: cfg.LicenseHeaders,
+		}
+		if cfg.License != "" && cfg.License != "none" {
+			l, err := licenses.Find(cfg.License)
+			if err != nil {
+				fmt.Printf("Error: invalid License in config: %v\n", err)
+				os.Exit(1)
+			}
+			app.License = &l
+		}
+
+		fmt.Printf("Applying %s (%d projects)...\n\n", stackPath, len(stack.Projects))
+		results := app.ApplyStack(stack, func(r core.ApplyResult) {
+			switch r.Status {
+			case core.ApplyCreated:
+				fmt.Printf("✓ %-20s created in %s (%s)\n", r.Project.Name, r.Dir, r.Duration.Round(time.Millisecond))
+			case core.ApplySkipped:
+				if r.Err != nil {
+					fmt.Printf("- %-20s skipped, %v\n", r.Project.Name, r.Err)
+				} else {
+					fmt.Printf("- %-20s skipped, %s already exists\n", r.Project.Name, r.Dir)
+				}
+			case core.ApplyFailed:
+				fmt.Printf("✗ %-20s %v\n", r.Project.Name, r.Err)
+			}
+		})
+
+		counts := map[core.ApplyStatus]int{}
+		for _, r := range results {
+			counts[r.Status]++
+		}
+		fmt.Printf("\n%d created, %d skipped, %d failed\n", counts[core.ApplyCreated], counts[core.ApplySkipped], counts[core.ApplyFailed])
+		if counts[core.ApplyFailed] > 0 {
+			os.Exit(1)
+		}
+
+	case "info":
+		output := parseOutput()
+		projectPath := "."
+		if len(os.Args) > 2 {
+			projectPath = os.Args[2]
+		}
+
+		manifest, err := core.LoadManifest(projectPath)
+		if err != nil {
+			exitWithError(output, err)
+		}
+
+		statuses, err := core.CheckManifest(projectPath, manifest)
+		if err != nil {
+			exitWithError(output, err)
+		}
+
+		switch output {
+		case ui.OutputJSON:
+			addOns := manifest.AddOns
+			if addOns == nil {
+				addOns = []string{}
+			}
+			ui.WriteJSON(os.Stdout, struct {
+				Template        string            `json:"template"`
+				TemplateVersion string            `json:"template_version"`
+				Module          string            `json:"module"`
+				CreatedAt       time.Time         `json:"created_at"`
+				EndmiVersion    string            `json:"endmi_version"`
+				GoVersion       string            `json:"go_version"`
+				AddOns          []string          `json:"add_ons"`
+				Files           []core.FileStatus `json:"files"`
+			}{
+				manifest.Template, manifest.TemplateVersion, manifest.Params["module"], manifest.CreatedAt,
+				manifest.EndmiVersion, manifest.GoVersion, addOns, statuses,
+			})
+			return
+		case ui.OutputTable:
+			var rows [][]string
+			for _, st := range statuses {
+				rows = append(rows, []string{st.Path, string(st.State)})
+			}
+			ui.WriteTable(os.Stdout, []string{"path", "state"}, rows)
+			return
+		case ui.OutputNames:
+			var names []string
+			for _, st := range statuses {
+				names = append(names, st.Path)
+			}
+			ui.WriteNames(os.Stdout, names)
+			return
+		}
+
+		fmt.Printf("Template:  %s (%s)\n", manifest.Template, manifest.TemplateVersion)
+		fmt.Printf("Module:    %s\n", manifest.Params["module"])
+		fmt.Printf("Created:   %s\n", manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Endmi:     %s\n", manifest.EndmiVersion)
+		fmt.Printf("Go:        %s\n", manifest.GoVersion)
+		if len(manifest.AddOns) > 0 {
+			fmt.Printf("Add-ons:   %s\n", strings.Join(manifest.AddOns, ", "))
+		}
+		fmt.Println()
+		fmt.Println("Generated files:")
+		for _, st := range statuses {
+			fmt.Printf("  %-10s %s\n", st.State, st.Path)
+		}
+
+	case "template":
+		output := parseOutput()
+		if len(os.Args) < 3 || os.Args[2] != "list" {
+			exitWithError(output, fmt.Errorf("%w: usage: endmi template list [--output json|table|names]", ui.ErrUsage))
+		}
+
+		templates := extensions.BuiltinTemplates()
+		switch output {
+		case ui.OutputJSON:
+			type templateInfo struct {
+				Name         string   `json:"name"`
+				Description  string   `json:"description"`
+				Dependencies []string `json:"dependencies"`
+				MinGoVersion string   `json:"min_go_version,omitempty"`
+			}
+			infos := []templateInfo{}
+			for _, t := range templates {
+				info := templateInfo{Name: t.Name(), Description: t.Description(), Dependencies: t.Dependencies()}
+				if info.Dependencies == nil {
+					info.Dependencies = []string{}
+				}
+				if v, ok := t.(extensions.MinGoVersioner); ok {
+					info.MinGoVersion = v.MinGoVersion()
+				}
+				infos = append(infos, info)
+			}
+			ui.WriteJSON(os.Stdout, infos)
+		case ui.OutputNames:
+			var names []string
+			for _, t := range templates {
+				names = append(names, t.Name())
+			}
+			ui.WriteNames(os.Stdout, names)
+		default:
+			var rows [][]string
+			for _, t := range templates {
+				rows = append(rows, []string{t.Name(), t.Description()})
+			}
+			ui.WriteTable(os.Stdout, []string{"name", "description"}, rows)
+		}
+
+	case "toolchains":
+		toolchains := core.ListToolchains()
+		if len(toolchains) == 0 {
+			fmt.Println(core.ErrGoNotFound)
+			os.Exit(1)
+		}
+
+		fmt.Println("Go toolchains:")
+		fmt.Println()
+		for _, tc := range toolchains {
+			fmt.Printf("  %-12s %s\n", tc.Version, tc.Path)
+		}
+
 	case "temp":
 		if len(os.Args) < 3 {
 			fmt.Println("Error: temp command requires a subcommand")
 			fmt.Println()
-			fmt.Println("Available subcommands:")
-			fmt.Println("  create              Create a new temporary project")
-			fmt.Println("  list                List all temporary projects")
-			fmt.Println("  delete <name>       Delete a temporary project")
-			fmt.Println("  clean               Remove all temporary projects")
-			fmt.Println("  promote <name> <path> Move temp project to permanent location")
+			printTempSubcommands()
 			os.Exit(1)
 		}
 
 		subcommand := os.Args[2]
 		app := &core.App{}
-		tcm := &core.TempCodeManager{App: app}
+		tcm := &core.TempCodeManager{App: app, Config: cfg}
 
 		switch subcommand {
 		case "create":
@@ -155,6 +659,11 @@ func main() {
 
 			var templateName string
 			var projectName string
+			var goFlag string
+			var opts core.TempOptions
+			var tagArgs []string
+			verify := cfg.Verify
+			vendor, noVendor := cfg.Vendor, false
 
 			// Parse flags for temp create
 			for i := 3; i < len(os.Args); i++ {
@@ -169,9 +678,67 @@ func main() {
 						projectName = os.Args[i+1]
 						i++
 					}
+				} else if arg == "--go" {
+					if i+1 < len(os.Args) {
+						goFlag = os.Args[i+1]
+						i++
+					}
+				} else if arg == "--ttl" {
+					if i+1 < len(os.Args) {
+						opts.TTL = os.Args[i+1]
+						i++
+					} else {
+						fmt.Println("Error: --ttl requires a duration (e.g. 12h, 3d, 2w)")
+						os.Exit(1)
+					}
+				} else if arg == "--tag" {
+					if i+1 < len(os.Args) {
+						tagArgs = append(tagArgs, os.Args[i+1])
+						i++
+					} else {
+						fmt.Println("Error: --tag requires a tag (e.g. http or http,retry)")
+						os.Exit(1)
+					}
+				} else if arg == "--note" {
+					if i+1 < len(os.Args) {
+						opts.Note = os.Args[i+1]
+						i++
+					} else {
+						fmt.Println("Error: --note requires some text")
+						os.Exit(1)
+					}
+				} else if arg == "--verify" {
+					verify = true
+				} else if arg == "--no-verify" {
+					verify = false
+				} else if arg == "--vendor" {
+					vendor, noVendor = true, false
+				} else if arg == "--no-vendor" {
+					vendor, noVendor = false, true
 				}
 			}
 
+			if projectName != "" {
+				exitOnInvalidName(projectName)
+			}
+			tags, err := core.ParseTags(tagArgs...)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Tags = tags
+			if opts.TTL != "" {
+				if _, err := utils.ParseDuration(opts.TTL); err != nil {
+					fmt.Printf("Error: --ttl: %v\n", err)
+					os.Exit(1)
+				}
+			}
+
+			app.Toolchain = resolveToolchain(goFlag, cfg)
+			app.Verify = verify
+			app.Vendor = vendor
+			app.NoVendor = noVendor
+
 			// If template is specified via flag, create directly
 			if templateName != "" {
 				selectedTemplate, err := utils.FindTemplateByName(templates, templateName)
@@ -181,20 +748,23 @@ func main() {
 					os.Exit(1)
 				}
 
+				app.Events = ui.NewProgressPrinter(os.Stdout).Handle
 				fmt.Printf("Creating temporary project with template '%s'...\n", templateName)
-				projectPath, err := tcm.CreateTempProject(selectedTemplate, projectName)
+				report, err := tcm.CreateTempProject(selectedTemplate, projectName, opts)
 				if err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
 				}
 
 				fmt.Printf("\n✅ Temporary project created successfully!\n")
-				fmt.Printf("📁 Location: %s\n\n", projectPath)
+				fmt.Printf("📁 Location: %s\n\n", report.Path)
 				fmt.Println("ℹ️  This is a temporary workspace. Changes won't be tracked.")
 				fmt.Println("   Use 'endmi temp promote <name> <path>' to make it permanent.")
+				fmt.Println()
+				printReport(report)
 			} else {
 				// Use interactive UI
-				program := ui.NewTempProgram(tcm, templates)
+				program := ui.NewTempProgram(tcm, templates, opts)
 				if _, err := program.Run(); err != nil {
 					fmt.Printf("Error: %v\n", err)
 					os.Exit(1)
@@ -202,46 +772,574 @@ func main() {
 			}
 
 		case "list":
+			output := parseOutput()
+			sortBy := "name"
+			var filter core.TempFilter
+			var tagArgs []string
+			for i := 3; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if i+1 >= len(os.Args) {
+					if arg == "--sort" || arg == "--tag" || arg == "--template" || arg == "-t" || arg == "--since" {
+						exitWithError(output, fmt.Errorf("%w: %s requires a value", ui.ErrUsage, arg))
+					}
+					continue
+				}
+				if arg == "--sort" {
+					sortBy = os.Args[i+1]
+					i++
+				} else if arg == "--tag" {
+					tagArgs = append(tagArgs, os.Args[i+1])
+					i++
+				} else if arg == "--template" || arg == "-t" {
+					filter.Template = os.Args[i+1]
+					i++
+				} else if arg == "--since" {
+					since, err := utils.ParseDuration(os.Args[i+1])
+					if err != nil {
+						exitWithError(output, fmt.Errorf("%w: --since: %v", ui.ErrUsage, err))
+					}
+					filter.Since = since
+					i++
+				}
+			}
+			if sortBy != "name" && sortBy != "created" && sortBy != "used" && sortBy != "size" {
+				exitWithError(output, fmt.Errorf("%w: unknown sort key '%s' (use name, created, used or size)", ui.ErrUsage, sortBy))
+			}
+			tags, err := core.ParseTags(tagArgs...)
+			if err != nil {
+				exitWithError(output, fmt.Errorf("%w: %v", ui.ErrUsage, err))
+			}
+			filter.Tags = tags
+
 			projects, err := tcm.ListTempProjects()
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
+				exitWithError(output, err)
 			}
 
-			if len(projects) == 0 {
+			if len(projects) == 0 && output == ui.OutputText {
 				fmt.Println("No temporary projects found.")
 				os.Exit(0)
 			}
+			projects = core.FilterTempProjects(projects, filter)
+			if len(projects) == 0 && output == ui.OutputText {
+				fmt.Println("No temporary projects match the filter.")
+				os.Exit(0)
+			}
+
+			usages := core.ProjectUsages(projects)
+			switch sortBy {
+			case "name":
+				sort.SliceStable(usages, func(i, j int) bool {
+					return usages[i].Name < usages[j].Name
+				})
+			case "size":
+				core.SortUsagesBySize(usages)
+			case "created":
+				sort.SliceStable(usages, func(i, j int) bool {
+					return usages[i].CreatedAt.Before(usages[j].CreatedAt)
+				})
+			case "used":
+				sort.SliceStable(usages, func(i, j int) bool {
+					return usages[i].LastUsed().After(usages[j].LastUsed())
+				})
+			}
+
+			switch output {
+			case ui.OutputJSON:
+				type tempProjectInfo struct {
+					core.TempProjectUsage
+					ExpiresAt *time.Time `json:"expires_at,omitempty"`
+					SizeError string     `json:"size_error,omitempty"`
+				}
+				infos := []tempProjectInfo{}
+				for _, p := range usages {
+					info := tempProjectInfo{TempProjectUsage: p}
+					info.LastUsedAt = p.LastUsed()
+					if expiresAt, ok, err := tcm.ExpiresAt(p.TempProjectMetadata); err == nil && ok {
+						info.ExpiresAt = &expiresAt
+					}
+					if p.Err != nil {
+						info.SizeError = p.Err.Error()
+					}
+					infos = append(infos, info)
+				}
+				ui.WriteJSON(os.Stdout, infos)
+				return
+			case ui.OutputTable:
+				var rows [][]string
+				for _, p := range usages {
+					expires := "-"
+					if expiresAt, ok, err := tcm.ExpiresAt(p.TempProjectMetadata); err == nil && ok {
+						expires = "expired"
+						if left := time.Until(expiresAt); left > 0 {
+							expires = utils.FormatDuration(left)
+						}
+					}
+					tags, pinned := "-", ""
+					if len(p.Tags) > 0 {
+						tags = strings.Join(p.Tags, ",")
+					}
+					if p.Pinned {
+						pinned = "yes"
+					}
+					rows = append(rows, []string{p.Name, p.Template, tags, utils.FormatSize(p.Size),
+						p.CreatedAt.Format("2006-01-02 15:04"), expires, pinned})
+				}
+				ui.WriteTable(os.Stdout, []string{"name", "template", "tags", "size", "created", "expires", "pinned"}, rows)
+				return
+			case ui.OutputNames:
+				var names []string
+				for _, p := range usages {
+					names = append(names, p.Name)
+				}
+				ui.WriteNames(os.Stdout, names)
+				return
+			}
 
+			var totalSize int64
+			var totalFiles int
 			fmt.Println("Temporary Projects:")
 			fmt.Println()
-			for _, p := range projects {
-				fmt.Printf("  Name:     %s\n", p.Name)
-				fmt.Printf("  Template: %s\n", p.Template)
+			for _, p := range usages {
+				totalSize += p.Size
+				totalFiles += p.Files
+				if p.Pinned {
+					fmt.Printf("  Name:     %s 📌 pinned\n", p.Name)
+				} else {
+					fmt.Printf("  Name:     %s\n", p.Name)
+				}
+				if p.Scratch {
+					fmt.Printf("  Template: %s (scratch module)\n", p.Template)
+				} else {
+					fmt.Printf("  Template: %s\n", p.Template)
+				}
+				if len(p.Tags) > 0 {
+					fmt.Printf("  Tags:     %s\n", strings.Join(p.Tags, ", "))
+				}
+				if p.Note != "" {
+					fmt.Printf("  Note:     %s\n", p.Note)
+				}
+				if p.Parent != "" {
+					fmt.Printf("  Parent:   %s\n", p.Parent)
+				}
 				fmt.Printf("  Created:  %s\n", p.CreatedAt.Format("2006-01-02 15:04:05"))
+				if expiresAt, ok, err := tcm.ExpiresAt(p.TempProjectMetadata); err == nil && ok {
+					if left := time.Until(expiresAt); left > 0 {
+						fmt.Printf("  Expires:  in %s\n", utils.FormatDuration(left))
+					} else {
+						fmt.Println("  Expires:  expired (removed by 'endmi temp gc')")
+					}
+				}
+				if p.Err != nil {
+					fmt.Printf("  Size:     %s in %d files (incomplete: %v)\n", utils.FormatSize(p.Size), p.Files, p.Err)
+				} else {
+					fmt.Printf("  Size:     %s in %d files\n", utils.FormatSize(p.Size), p.Files)
+				}
 				fmt.Printf("  Path:     %s\n", p.Path)
 				fmt.Println()
 			}
 
+			fmt.Printf("Total: %d projects, %s in %d files\n", len(usages), utils.FormatSize(totalSize), totalFiles)
+			if snapshots, err := tcm.SnapshotsUsage(); err == nil && snapshots > 0 {
+				fmt.Printf("Snapshots: %s\n", utils.FormatSize(snapshots))
+			}
+			if status, err := tcm.Quota(); err == nil {
+				fmt.Printf("Workspace: %s", utils.FormatSize(status.Used))
+				if cfg.TempQuota != "" {
+					fmt.Printf(" of %s quota", cfg.TempQuota)
+				}
+				fmt.Println()
+			}
+
+		case "run":
+			var projectName, goFlag string
+			var programArgs []string
+			keep := false
+			scratch := cfg.TempScratch
+			for i := 3; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if arg == "--" {
+					programArgs = os.Args[i+1:]
+					break
+				} else if arg == "--go" {
+					if i+1 < len(os.Args) {
+						goFlag = os.Args[i+1]
+						i++
+					}
+				} else if arg == "--keep" {
+					keep = true
+				} else if arg == "--scratch" {
+					scratch = true
+				} else if arg == "--no-scratch" {
+					scratch = false
+				} else if projectName == "" {
+					projectName = arg
+				} else {
+					fmt.Printf("Error: unexpected argument '%s' (pass program arguments after --)\n", arg)
+					fmt.Println("Usage: endmi temp run [name | file.go | -] [--keep] [--scratch] [-- args...]")
+					os.Exit(1)
+				}
+			}
+
+			app.Toolchain = resolveToolchain(goFlag, cfg)
+			runOpts := core.RunOptions{
+				Args:   programArgs,
+				Stdin:  os.Stdin,
+				Stdout: os.Stdout,
+				Stderr: os.Stderr,
+			}
+
+			var code int
+			var err error
+			if isSnippet(projectName) {
+				var src []byte
+				filename := projectName
+				if projectName == "-" {
+					src, err = io.ReadAll(os.Stdin)
+					filename = "snippet.go"
+					runOpts.Stdin = nil // Already consumed as source
+				} else {
+					src, err = os.ReadFile(projectName)
+				}
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+
+				var name string
+				name, code, err = tcm.RunSnippet(src, core.SnippetOptions{
+					RunOptions: runOpts,
+					Filename:   filename,
+					Keep:       keep,
+					Scratch:    scratch,
+				})
+				if keep && name != "" {
+					fmt.Fprintf(os.Stderr, "ℹ️  Kept as temporary project '%s'\n", name)
+				}
+			} else {
+				if projectName == "" {
+					projectName = "@last"
+				}
+				code, err = tcm.RunTempProject(resolveTempName(tcm.ResolveTempProject, projectName), runOpts)
+			}
+			if errors.Is(err, core.ErrBuildFailed) {
+				os.Exit(code)
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(code)
+
+		case "watch":
+			var projectName, goFlag string
+			var opts core.WatchOptions
+			for i := 3; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if arg == "--" {
+					opts.Args = os.Args[i+1:]
+					break
+				} else if arg == "--test" {
+					opts.Test = true
+				} else if arg == "--go" {
+					if i+1 < len(os.Args) {
+						goFlag = os.Args[i+1]
+						i++
+					}
+				} else if projectName == "" {
+					projectName = arg
+				} else {
+					fmt.Printf("Error: unexpected argument '%s' (pass program arguments after --)\n", arg)
+					fmt.Println("Usage: endmi temp watch [name] [--test] [-- args...]")
+					os.Exit(1)
+				}
+			}
+
+			app.Toolchain = resolveToolchain(goFlag, cfg)
+			action := "go run ."
+			if opts.Test {
+				action = "go test ./..."
+			}
+			opts.Stdout = os.Stdout
+			opts.Stderr = os.Stderr
+			opts.OnRun = func(changed []string) {
+				fmt.Print("\033[H\033[2J") // Clear the screen
+				fmt.Printf("▶ %s  %s", action, time.Now().Format("15:04:05"))
+				if len(changed) > 0 {
+					fmt.Printf("  (changed: %s)", strings.Join(changed, ", "))
+				}
+				fmt.Println()
+				fmt.Println()
+			}
+			opts.OnExit = func(code int, err error) {
+				fmt.Println()
+				switch {
+				case errors.Is(err, core.ErrBuildFailed):
+					fmt.Println("✗ build failed, waiting for changes...")
+				case err != nil:
+					fmt.Printf("✗ %v, waiting for changes...\n", err)
+				case code != 0:
+					fmt.Printf("✗ exited with code %d, waiting for changes...\n", code)
+				default:
+					fmt.Println("✓ done, waiting for changes...")
+				}
+			}
+
+			if projectName == "" {
+				projectName = "@last"
+			}
+			projectName = resolveTempName(tcm.ResolveTempProject, projectName)
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := tcm.WatchTempProject(ctx, projectName, opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+		case "gc":
+			dryRun := false
+			for _, arg := range os.Args[3:] {
+				if arg == "--dry-run" {
+					dryRun = true
+				}
+			}
+
+			removed, err := tcm.GC(dryRun)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if len(removed) == 0 {
+				fmt.Println("No expired temporary projects.")
+				os.Exit(0)
+			}
+
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, p := range removed {
+				fmt.Printf("  %s %s (created %s)\n", verb, p.Name, p.CreatedAt.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Printf("✓ %s %d expired temporary project(s)\n", verb, len(removed))
+
 		case "delete":
-			if len(os.Args) < 4 {
+			var projectName string
+			force := false
+			for _, arg := range os.Args[3:] {
+				if arg == "--force" || arg == "-f" {
+					force = true
+				} else if projectName == "" {
+					projectName = arg
+				}
+			}
+			if projectName == "" {
 				fmt.Println("Error: delete requires a project name")
-				fmt.Println("Usage: endmi temp delete <name>")
+				fmt.Println("Usage: endmi temp delete <name> [--force]")
 				os.Exit(1)
 			}
 
-			projectName := os.Args[3]
+			projectName = resolveTempName(tcm.ResolveTempProjectStrict, projectName)
 			fmt.Printf("Deleting temporary project '%s'...\n", projectName)
 
-			if err := tcm.DeleteTempProject(projectName); err != nil {
+			if err := tcm.DeleteTempProject(projectName, force); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
 			fmt.Printf("✓ Temporary project '%s' deleted successfully\n", projectName)
 
+		case "snapshot":
+			var projectName, message string
+			for i := 3; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if arg == "-m" || arg == "--message" {
+					if i+1 < len(os.Args) {
+						message = os.Args[i+1]
+						i++
+					}
+				} else if projectName == "" {
+					projectName = arg
+				}
+			}
+			if projectName == "" {
+				fmt.Println("Error: snapshot requires a project name")
+				fmt.Println("Usage: endmi temp snapshot <name> [-m message]")
+				os.Exit(1)
+			}
+
+			snap, err := tcm.SnapshotTempProject(resolveTempName(tcm.ResolveTempProject, projectName), message)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ Snapshot %s of '%s' saved (%d files, %s)\n", snap.ID, snap.Project, len(snap.Files), utils.FormatSize(snap.Size()))
+
+		case "snapshots":
+			if len(os.Args) < 4 {
+				fmt.Println("Error: snapshots requires a project name")
+				fmt.Println("Usage: endmi temp snapshots <name>")
+				os.Exit(1)
+			}
+
+			projectName := resolveTempName(tcm.ResolveTempProject, os.Args[3])
+			snaps, err := tcm.ListSnapshots(projectName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if len(snaps) == 0 {
+				fmt.Printf("No snapshots of '%s'.\n", projectName)
+				os.Exit(0)
+			}
+
+			fmt.Printf("Snapshots of '%s':\n", projectName)
+			fmt.Println()
+			for _, snap := range snaps {
+				fmt.Printf("  %-4s %s  %4d files  %9s  %s\n", snap.ID,
+					snap.CreatedAt.Format("2006-01-02 15:04:05"), len(snap.Files), utils.FormatSize(snap.Size()), snap.Message)
+			}
+
+		case "restore":
+			if len(os.Args) < 5 {
+				fmt.Println("Error: restore requires a project name and a snapshot")
+				fmt.Println("Usage: endmi temp restore <name> <snapshot>")
+				os.Exit(1)
+			}
+
+			projectName, id := resolveTempName(tcm.ResolveTempProjectStrict, os.Args[3]), os.Args[4]
+			backup, err := tcm.RestoreSnapshot(projectName, id)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ '%s' restored to snapshot %s\n", projectName, id)
+			fmt.Printf("  The previous state was saved as snapshot %s.\n", backup.ID)
+
+		case "clone":
+			if len(os.Args) < 4 {
+				fmt.Println("Error: clone requires a project name")
+				fmt.Println("Usage: endmi temp clone <name> [new-name]")
+				os.Exit(1)
+			}
+
+			projectName := resolveTempName(tcm.ResolveTempProject, os.Args[3])
+			var newName string
+			if len(os.Args) > 4 {
+				newName = os.Args[4]
+				exitOnInvalidName(newName)
+			}
+
+			app.Events = ui.NewProgressPrinter(os.Stdout).Handle
+			clone, err := tcm.CloneTempProject(projectName, newName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ Cloned '%s' as '%s'\n", projectName, clone.Name)
+			fmt.Printf("📁 Location: %s\n", clone.Path)
+
+		case "tag":
+			if len(os.Args) < 4 {
+				fmt.Println("Error: tag requires a project name")
+				fmt.Println("Usage: endmi temp tag <name> [tag...] [--remove tag]")
+				os.Exit(1)
+			}
+
+			projectName := resolveTempName(tcm.ResolveTempProject, os.Args[3])
+			var addArgs, removeArgs []string
+			for i := 4; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if arg == "--remove" || arg == "-r" {
+					if i+1 < len(os.Args) {
+						removeArgs = append(removeArgs, os.Args[i+1])
+						i++
+					} else {
+						fmt.Println("Error: --remove requires a tag")
+						os.Exit(1)
+					}
+				} else {
+					addArgs = append(addArgs, arg)
+				}
+			}
+			add, err := core.ParseTags(addArgs...)
+			if err == nil {
+				var remove []string
+				remove, err = core.ParseTags(removeArgs...)
+				if err == nil {
+					add, err = tcm.TagTempProject(projectName, add, remove)
+				}
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if len(add) == 0 {
+				fmt.Printf("Temporary project '%s' has no tags\n", projectName)
+			} else {
+				fmt.Printf("🏷️  %s: %s\n", projectName, strings.Join(add, ", "))
+			}
+
+		case "note":
+			if len(os.Args) < 4 {
+				fmt.Println("Error: note requires a project name")
+				fmt.Println("Usage: endmi temp note <name> [text | --clear]")
+				os.Exit(1)
+			}
+
+			projectName := resolveTempName(tcm.ResolveTempProject, os.Args[3])
+			if len(os.Args) == 4 {
+				project, err := tcm.ResolveTempProject(projectName)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				if project.Note == "" {
+					fmt.Printf("Temporary project '%s' has no note\n", project.Name)
+				} else {
+					fmt.Println(project.Note)
+				}
+				os.Exit(0)
+			}
+
+			note := strings.Join(os.Args[4:], " ")
+			if note == "--clear" {
+				note = ""
+			}
+			if err := tcm.SetNote(projectName, note); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if note == "" {
+				fmt.Printf("✓ Note of '%s' removed\n", projectName)
+			} else {
+				fmt.Printf("✓ Note of '%s' saved\n", projectName)
+			}
+
+		case "pin", "unpin":
+			if len(os.Args) < 4 {
+				fmt.Printf("Error: %s requires a project name\n", subcommand)
+				fmt.Printf("Usage: endmi temp %s <name>\n", subcommand)
+				os.Exit(1)
+			}
+
+			projectName := resolveTempName(tcm.ResolveTempProject, os.Args[3])
+			pinned := subcommand == "pin"
+			if err := tcm.SetPinned(projectName, pinned); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if pinned {
+				fmt.Printf("📌 Temporary project '%s' pinned; clean and gc will leave it alone\n", projectName)
+			} else {
+				fmt.Printf("✓ Temporary project '%s' unpinned\n", projectName)
+			}
+
 		case "clean":
-			fmt.Print("Are you sure you want to delete ALL temporary projects? (y/N): ")
+			fmt.Print("Are you sure you want to delete ALL unpinned temporary projects? (y/N): ")
 			var confirm string
 			fmt.Scanln(&confirm)
 
@@ -251,41 +1349,94 @@ func main() {
 			}
 
 			fmt.Println("Cleaning all temporary projects...")
-			if err := tcm.CleanAll(); err != nil {
+			kept, err := tcm.CleanAll()
+			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
-			fmt.Println("✓ All temporary projects removed successfully")
+			if len(kept) == 0 {
+				fmt.Println("✓ All temporary projects removed successfully")
+			} else {
+				fmt.Printf("✓ Temporary projects removed; kept %d pinned:\n", len(kept))
+				for _, p := range kept {
+					fmt.Printf("  📌 %s\n", p.Name)
+				}
+			}
 
 		case "promote":
-			if len(os.Args) < 5 {
+			var positional []string
+			var opts core.PromoteOptions
+			var into string
+			for i := 3; i < len(os.Args); i++ {
+				arg := os.Args[i]
+				if arg == "--into" {
+					if i+1 < len(os.Args) {
+						into = os.Args[i+1]
+						i++
+					} else {
+						fmt.Println("Error: --into requires a package directory")
+						os.Exit(1)
+					}
+				} else if arg == "--module" || arg == "-m" {
+					if i+1 < len(os.Args) {
+						opts.Module = os.Args[i+1]
+						i++
+					} else {
+						fmt.Println("Error: --module requires a module path")
+						os.Exit(1)
+					}
+				} else {
+					positional = append(positional, arg)
+				}
+			}
+			if into != "" {
+				if len(positional) != 1 || opts.Module != "" {
+					fmt.Println("Usage: endmi temp promote <name> --into <package dir>")
+					os.Exit(1)
+				}
+
+				projectName := resolveTempName(tcm.ResolveTempProjectStrict, positional[0])
+				app.Events = ui.NewProgressPrinter(os.Stdout).Handle
+				fmt.Printf("Promoting temporary project '%s' into package '%s'...\n", projectName, into)
+				report, err := tcm.PromoteIntoPackage(projectName, into)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+
+				fmt.Printf("\n✓ Code promoted into %s and the module builds\n", into)
+				fmt.Println()
+				fmt.Print(ui.RenderTimings(report.Timings, report.Total))
+				os.Exit(0)
+			}
+
+			if len(positional) < 2 {
 				fmt.Println("Error: promote requires a project name and target path")
-				fmt.Println("Usage: endmi temp promote <name> <path>")
+				fmt.Println("Usage: endmi temp promote <name> <path> [--module <path>]")
 				os.Exit(1)
 			}
 
-			projectName := os.Args[3]
-			targetPath := os.Args[4]
+			projectName := resolveTempName(tcm.ResolveTempProjectStrict, positional[0])
+			targetPath := positional[1]
 
+			app.Events = ui.NewProgressPrinter(os.Stdout).Handle
 			fmt.Printf("Promoting temporary project '%s' to '%s'...\n", projectName, targetPath)
-			if err := tcm.PromoteTempProject(projectName, targetPath); err != nil {
+			if err := tcm.PromoteTempProject(projectName, targetPath, opts); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
 			fmt.Printf("✓ Project promoted successfully to: %s\n", targetPath)
+			if opts.Module != "" {
+				fmt.Printf("  Module renamed to %s.\n", opts.Module)
+			}
 			fmt.Println("  The project is now permanent and no longer in the temp workspace.")
 
 		default:
 			fmt.Printf("Unknown temp subcommand: %s\n", subcommand)
 			fmt.Println()
-			fmt.Println("Available subcommands:")
-			fmt.Println("  create              Create a new temporary project")
-			fmt.Println("  list                List all temporary projects")
-			fmt.Println("  delete <name>       Delete a temporary project")
-			fmt.Println("  clean               Remove all temporary projects")
-			fmt.Println("  promote <name> <path> Move temp project to permanent location")
+			printTempSubcommands()
 			os.Exit(1)
 		}
 		os.Exit(0)
@@ -0,0 +1,301 @@
+// Package scaffold is the public Go API for creating projects with endmi.
+//
+// It wraps the same pipeline as the endmi CLI but never prints, never exits
+// and never reads ~/.endmi unless asked to:
+//
+//	res, err := scaffold.Create(ctx, "billing-api",
+//		scaffold.WithTemplate("gin"),
+//		scaffold.WithTargetDir("services/billing-api"),
+//		scaffold.WithModulePath("example.com/acme/billing-api"),
+//	)
+//
+// Errors are typed: use errors.Is with the Err* values and errors.As with
+// *NameError, *ToolchainError and *StepError to tell failures apart.
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dlcuy22/endmi/core"
+	"github.com/dlcuy22/endmi/extensions"
+	"github.com/dlcuy22/endmi/licenses"
+	"github.com/dlcuy22/endmi/utils"
+)
+
+var (
+	// ErrTemplateNotFound is returned when the requested template is not
+	// registered.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrNoTemplate is returned when neither WithTemplate nor
+	// WithTemplateValue is given.
+	ErrNoTemplate = errors.New("no template selected")
+	// ErrNoAuthor is returned when a license is requested but neither
+	// WithLicense nor the config names its copyright holder.
+	ErrNoAuthor = errors.New("license needs an author")
+	// ErrProjectExists is returned when the target directory is not empty.
+	ErrProjectExists = core.ErrProjectExists
+	// ErrGoNotFound is returned when no Go toolchain can be located.
+	ErrGoNotFound = core.ErrGoNotFound
+	// ErrVerifyFailed is never returned by Create; it marks a Result whose
+	// verification failed, see Result.Verified.
+	ErrVerifyFailed = core.ErrVerifyFailed
+)
+
+type (
+	// NameError reports an invalid project name or module path.
+	NameError = core.NameError
+	// ToolchainError reports a Go toolchain too old for the template.
+	ToolchainError = core.ToolchainError
+	// StepError reports which creation step failed.
+	StepError = core.StepError
+	// Event is a progress notification; see WithOutput.
+	Event = core.Event
+	// Pipeline is the ordered list of creation steps; see WithPipeline.
+	Pipeline = core.Pipeline
+	// Config mirrors ~/.endmi/endmi.json.
+	Config = utils.Config
+)
+
+// Result describes a created project.
+type Result struct {
+	// Dir is the project directory.
+	Dir        string
+	ModulePath string
+	Template   string
+	// GoVersion is the version of the toolchain that created the project.
+	GoVersion string
+	Timings   []core.StepTiming
+	Duration  time.Duration
+	// Verification holds the build/vet/test results when WithVerify is set.
+	Verification []core.VerifyResult
+	// VerifyLog is the log file of failed checks, empty when all passed.
+	VerifyLog string
+	Manifest  *core.Manifest
+}
+
+// Verified reports whether every verification check passed. It is true when
+// verification did not run.
+func (r *Result) Verified() bool {
+	for _, v := range r.Verification {
+		if !v.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+type options struct {
+	dir            string
+	modulePath     string
+	templateName   string
+	template       extensions.Template
+	params         map[string]string
+	output         core.EventHandler
+	goBinary       string
+	config         *utils.Config
+	verify         *bool
+	vendor         *bool
+	license        string
+	author         string
+	licenseHeaders *bool
+	customize      func(p *Pipeline) error
+}
+
+// Option configures Create.
+type Option func(*options)
+
+// WithTargetDir sets the directory to create. Defaults to the project name
+// relative to the working directory.
+func WithTargetDir(dir string) Option {
+	return func(o *options) { o.dir = dir }
+}
+
+// WithModulePath sets the module path given to `go mod init`. Defaults to
+// the project name.
+func WithModulePath(path string) Option {
+	return func(o *options) { o.modulePath = path }
+}
+
+// WithTemplate selects a registered template by name.
+func WithTemplate(name string) Option {
+	return func(o *options) { o.templateName = name }
+}
+
+// WithTemplateValue uses a template that need not be registered.
+func WithTemplateValue(t extensions.Template) Option {
+	return func(o *options) { o.template = t }
+}
+
+// WithParams passes extra template parameters.
+func WithParams(params map[string]string) Option {
+	return func(o *options) { o.params = params }
+}
+
+// WithOutput receives progress events. The handler may be called from
+// several goroutines at once. Without it, events are discarded.
+func WithOutput(handler func(Event)) Option {
+	return func(o *options) { o.output = handler }
+}
+
+// WithGoBinary selects the Go toolchain: a go binary, a Go root or an
+// installed version such as "1.22".
+func WithGoBinary(path string) Option {
+	return func(o *options) { o.goBinary = path }
+}
+
+// WithConfig supplies defaults (Go binary, verify, license, author) from a
+// config value. Explicit options take precedence.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) { o.config = cfg }
+}
+
+// WithVerify runs build, vet and test on the new project.
+func WithVerify(verify bool) Option {
+	return func(o *options) { o.verify = &verify }
+}
+
+// WithVendor forces `go mod vendor` on or off. Without it, the config and
+// the template's recommendation decide.
+func WithVendor(vendor bool) Option {
+	return func(o *options) { o.vendor = &vendor }
+}
+
+// WithLicense writes a LICENSE by SPDX ID (or "proprietary") for author.
+// headers adds SPDX headers to generated .go files. An empty author falls
+// back to the config's Author; if that is empty too, Create fails with
+// ErrNoAuthor rather than write a LICENSE without a copyright holder.
+// Unlike the endmi command, Create does not ask git for a name.
+func WithLicense(id, author string, headers bool) Option {
+	return func(o *options) {
+		o.license = id
+		o.author = author
+		o.licenseHeaders = &headers
+	}
+}
+
+// WithPipeline customizes the creation pipeline before it runs.
+func WithPipeline(customize func(p *Pipeline) error) Option {
+	return func(o *options) { o.customize = customize }
+}
+
+// Templates returns the names of the registered templates.
+func Templates() []string {
+	var names []string
+	for _, t := range extensions.BuiltinTemplates() {
+		names = append(names, t.Name())
+	}
+	return names
+}
+
+// LoadConfig reads a config file in the endmi.json format.
+func LoadConfig(path string) (*Config, error) {
+	return utils.LoadConfigFrom(path)
+}
+
+// Create scaffolds a project called name. Cancelling ctx stops any running
+// go command.
+func Create(ctx context.Context, name string, opts ...Option) (*Result, error) {
+	o := options{config: &utils.Config{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t, err := o.resolveTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	goBinary := o.goBinary
+	if goBinary == "" {
+		goBinary = o.config.GoBinary
+	}
+	toolchain, err := core.FindToolchain(goBinary)
+	if err != nil {
+		return nil, err
+	}
+
+	app := core.App{
+		Events:         o.output,
+		Toolchain:      toolchain,
+		Verify:         o.config.Verify,
+		Vendor:         o.config.Vendor,
+		Author:         o.config.Author,
+		LicenseHeaders: o.config.LicenseHeaders,
+		Customize:      o.customize,
+		Context:        ctx,
+	}
+	if o.verify != nil {
+		app.Verify = *o.verify
+	}
+	if o.vendor != nil {
+		app.Vendor = *o.vendor
+		app.NoVendor = !*o.vendor
+	}
+	if o.licenseHeaders != nil {
+		app.LicenseHeaders = *o.licenseHeaders
+	}
+	if o.author != "" {
+		app.Author = o.author
+	}
+
+	licenseID := o.config.License
+	if o.license != "" {
+		licenseID = o.license
+	}
+	if licenseID != "" && licenseID != "none" {
+		l, err := licenses.Find(licenseID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(app.Author) == "" {
+			return nil, fmt.Errorf("%w: pass one to WithLicense or set Author in the config", ErrNoAuthor)
+		}
+		app.License = &l
+	}
+
+	modulePath := o.modulePath
+	if modulePath == "" {
+		modulePath = name
+	}
+
+	report, err := app.Create(t, core.ProjectSpec{
+		Name:       name,
+		Dir:        o.dir,
+		ModulePath: modulePath,
+		Params:     o.params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Dir:          report.Path,
+		ModulePath:   modulePath,
+		Template:     t.Name(),
+		GoVersion:    toolchain.Version,
+		Timings:      report.Timings,
+		Duration:     report.Total,
+		Verification: report.Verification,
+		VerifyLog:    report.VerifyLog,
+		Manifest:     report.Manifest,
+	}, nil
+}
+
+func (o *options) resolveTemplate() (extensions.Template, error) {
+	if o.template != nil {
+		return o.template, nil
+	}
+	if o.templateName == "" {
+		return nil, ErrNoTemplate
+	}
+	for _, t := range extensions.BuiltinTemplates() {
+		if t.Name() == o.templateName {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrTemplateNotFound, o.templateName)
+}
@@ -0,0 +1,82 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateLicenseAuthor(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantErr    error
+		wantAuthor string
+	}{
+		{
+			name:    "no author anywhere",
+			opts:    []Option{WithLicense("MIT", "", false)},
+			wantErr: ErrNoAuthor,
+		},
+		{
+			name:    "blank author",
+			opts:    []Option{WithLicense("MIT", "  ", false)},
+			wantErr: ErrNoAuthor,
+		},
+		{
+			name:    "license from config without author",
+			opts:    []Option{WithConfig(&Config{License: "Apache-2.0"})},
+			wantErr: ErrNoAuthor,
+		},
+		{
+			name:       "author given",
+			opts:       []Option{WithLicense("MIT", "Ada Lovelace", false)},
+			wantAuthor: "Ada Lovelace",
+		},
+		{
+			name:       "author from config",
+			opts:       []Option{WithConfig(&Config{Author: "Grace Hopper"}), WithLicense("MIT", "", false)},
+			wantAuthor: "Grace Hopper",
+		},
+		{
+			name: "no license needs no author",
+			opts: []Option{WithLicense("none", "", false)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "app")
+			opts := append([]Option{WithTemplate("blank"), WithTargetDir(dir)}, tt.opts...)
+			_, err := Create(context.Background(), "app", opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+				}
+				if _, statErr := os.Stat(dir); !os.IsNotExist(statErr) {
+					t.Errorf("Create left %s behind", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			license, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+			if tt.wantAuthor == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("LICENSE written without a license: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(license), tt.wantAuthor) {
+				t.Errorf("LICENSE does not name %s:\n%s", tt.wantAuthor, license)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dlcuy22/endmi/core"
+)
+
+// OutputFormat selects how list-style commands print their results
+type OutputFormat string
+
+const (
+	// OutputText is each command's usual human-readable output
+	OutputText  OutputFormat = ""
+	OutputJSON  OutputFormat = "json"
+	OutputTable OutputFormat = "table"
+	// OutputNames prints one name per line, for shell loops and fzf
+	OutputNames OutputFormat = "names"
+)
+
+// ErrUsage marks errors caused by invalid command-line arguments
+var ErrUsage = errors.New("invalid usage")
+
+// ParseOutputFormat parses the value of --output
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(s)); f {
+	case OutputJSON, OutputTable, OutputNames:
+		return f, nil
+	}
+	return OutputText, fmt.Errorf("%w: unknown output format '%s' (use json, table or names)", ErrUsage, s)
+}
+
+// WriteJSON writes v as indented JSON followed by a newline
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteTable writes rows under an upper-case header, aligned in columns
+func WriteTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteNames writes one name per line
+func WriteNames(w io.Writer, names []string) error {
+	for _, name := range names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONError is how errors are reported in JSON output mode
+type JSONError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// WriteErrorJSON writes err as a JSONError
+func WriteErrorJSON(w io.Writer, err error) error {
+	var out JSONError
+	out.Error.Code = ErrorCode(err)
+	out.Error.Message = err.Error()
+	return WriteJSON(w, out)
+}
+
+// ErrorCode returns a stable, machine-readable code for err
+func ErrorCode(err error) string {
+	var nameErr *core.NameError
+	switch {
+	case errors.Is(err, ErrUsage):
+		return "usage"
+	case errors.Is(err, core.ErrProjectNotFound), errors.Is(err, os.ErrNotExist):
+		return "not_found"
+	case errors.Is(err, core.ErrNoManifest):
+		return "no_manifest"
+	case errors.Is(err, core.ErrProjectExists):
+		return "already_exists"
+	case errors.Is(err, core.ErrPinned):
+		return "pinned"
+	case errors.Is(err, core.ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, core.ErrGoNotFound):
+		return "go_not_found"
+	case errors.Is(err, core.ErrBuildFailed):
+		return "build_failed"
+	case errors.Is(err, core.ErrVerifyFailed):
+		return "verify_failed"
+	case errors.As(err, &nameErr):
+		return "invalid_name"
+	}
+	return "error"
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/dlcuy22/endmi/core"
+	"github.com/dlcuy22/endmi/utils"
+)
+
+// ErrPickCancelled is returned by PickTempProject when the user quits
+// without choosing
+var ErrPickCancelled = errors.New("no temp project selected")
+
+type pickModel struct {
+	name       string
+	candidates []core.TempProjectMetadata
+	cursor     int
+	picked     bool
+}
+
+// PickTempProject asks the user which of the temp projects matching name
+// they meant
+func PickTempProject(name string, candidates []core.TempProjectMetadata) (core.TempProjectMetadata, error) {
+	m := &pickModel{name: name, candidates: candidates}
+	if _, err := tea.NewProgram(m).Run(); err != nil {
+		return core.TempProjectMetadata{}, err
+	}
+	if !m.picked {
+		return core.TempProjectMetadata{}, ErrPickCancelled
+	}
+	return m.candidates[m.cursor], nil
+}
+
+func (m *pickModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "q", "esc":
+		return m, tea.Quit
+	case "enter":
+		m.picked = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.candidates)-1 {
+			m.cursor++
+		}
+	}
+	return m, nil
+}
+
+func (m *pickModel) View() string {
+	if m.picked {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("'%s' matches %d temporary projects. Which one?\n\n", m.name, len(m.candidates)))
+
+	options := make([]string, len(m.candidates))
+	for i, p := range m.candidates {
+		line := fmt.Sprintf("%s — %s, used %s ago", p.Name, p.Template, utils.FormatDuration(time.Since(p.LastUsed())))
+		if p.Note != "" {
+			line += " — " + p.Note
+		}
+		options[i] = line
+	}
+	b.WriteString(RenderOptionList(options, m.cursor))
+	b.WriteString("\nUse ↑/↓ to navigate, Enter to select, q to cancel")
+	return b.String()
+}
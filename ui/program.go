@@ -3,11 +3,13 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/dlcuy22/endmi/core"
 	"github.com/dlcuy22/endmi/extensions"
+	"github.com/dlcuy22/endmi/licenses"
 	"github.com/dlcuy22/endmi/utils"
 )
 
@@ -16,17 +18,28 @@ type step int
 const (
 	stepProjectName step = iota
 	stepTemplate
+	stepLicense
 	stepCreating
 	stepChoice
 	stepDone
 )
 
-type outputMsg struct {
-	line string
+type eventMsg struct {
+	event core.Event
+}
+
+type tickMsg struct{}
+
+// spinnerTick drives the checklist spinner while a project is being created
+func spinnerTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
 }
 
 type doneMsg struct {
-	err error
+	err    error
+	report *core.Report
 }
 
 type model struct {
@@ -36,11 +49,25 @@ type model struct {
 	templates   []extensions.Template
 	input       string
 	err         error
-	output      []string
+	progress    Checklist
+	frame       int
 	app         *core.App
+	report      *core.Report
+	// selected is the chosen template index, kept once the cursor moves on
+	selected    int
+	pickLicense bool
+}
+
+// licenseOptions lists the license step's rows; row 0 means no license
+func licenseOptions() []string {
+	options := []string{"None"}
+	for _, l := range licenses.All() {
+		options = append(options, fmt.Sprintf("%s — %s", l.ID, l.Name))
+	}
+	return options
 }
 
-func initialModel(app *core.App, templates []extensions.Template, projectName string) model {
+func initialModel(app *core.App, templates []extensions.Template, projectName string, pickLicense bool) model {
 	startStep := stepProjectName
 	if projectName != "" {
 		startStep = stepTemplate
@@ -52,19 +79,21 @@ func initialModel(app *core.App, templates []extensions.Template, projectName st
 		templates:   templates,
 		cursor:      0,
 		input:       projectName,
-		output:      []string{},
 		app:         app,
+		pickLicense: pickLicense,
 	}
 }
 
-// NewProgram wires a Bubble Tea program for the CLI.
-func NewProgram(app *core.App, templates []extensions.Template, projectName string) *tea.Program {
-	m := initialModel(app, templates, projectName)
+// NewProgram wires a Bubble Tea program for the CLI. When pickLicense is
+// set, the user chooses a license after the template, starting from
+// app.License.
+func NewProgram(app *core.App, templates []extensions.Template, projectName string, pickLicense bool) *tea.Program {
+	m := initialModel(app, templates, projectName, pickLicense)
 	p := tea.NewProgram(&m)
 
-	app.Output = func(line string) {
+	app.Events = func(e core.Event) {
 		if p != nil {
-			p.Send(outputMsg{line: line})
+			p.Send(eventMsg{event: e})
 		}
 	}
 
@@ -83,18 +112,36 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.step == stepCreating {
 				return m, nil
 			}
+			if msg.String() == "q" && m.step == stepProjectName {
+				m.input += "q"
+				return m, nil
+			}
 			return m, tea.Quit
 
 		case "enter":
 			switch m.step {
 			case stepProjectName:
-				if m.input != "" {
+				if core.ValidateProjectName(m.input) == nil {
 					m.projectName = m.input
 					m.step = stepTemplate
 				}
 			case stepTemplate:
+				m.selected = m.cursor
+				if m.pickLicense {
+					m.step = stepLicense
+					m.cursor = m.licenseCursor()
+					return m, nil
+				}
+				m.step = stepCreating
+				return m, tea.Batch(m.createProject(), spinnerTick())
+			case stepLicense:
+				m.app.License = nil
+				if m.cursor > 0 {
+					l := licenses.All()[m.cursor-1]
+					m.app.License = &l
+				}
 				m.step = stepCreating
-				return m, m.createProject()
+				return m, tea.Batch(m.createProject(), spinnerTick())
 			case stepChoice:
 				if m.cursor == 0 {
 					// Open terminal in project folder
@@ -111,6 +158,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.step == stepTemplate && m.cursor > 0 {
 				m.cursor--
 			}
+			if m.step == stepLicense && m.cursor > 0 {
+				m.cursor--
+			}
 			if m.step == stepChoice && m.cursor > 0 {
 				m.cursor--
 			}
@@ -119,6 +169,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.step == stepTemplate && m.cursor < len(m.templates)-1 {
 				m.cursor++
 			}
+			if m.step == stepLicense && m.cursor < len(licenses.All()) {
+				m.cursor++
+			}
 			if m.step == stepChoice && m.cursor < 1 {
 				m.cursor++
 			}
@@ -128,18 +181,35 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.input = m.input[:len(m.input)-1]
 			}
 
+		case "h":
+			if m.step == stepLicense {
+				m.app.LicenseHeaders = !m.app.LicenseHeaders
+				return m, nil
+			}
+			if m.step == stepProjectName {
+				m.input += "h"
+			}
+
 		default:
 			if m.step == stepProjectName && len(msg.String()) == 1 {
 				m.input += msg.String()
 			}
 		}
 
-	case outputMsg:
-		m.output = append(m.output, msg.line)
+	case eventMsg:
+		m.progress.Apply(msg.event)
 		return m, nil
 
+	case tickMsg:
+		if m.step != stepCreating {
+			return m, nil
+		}
+		m.frame++
+		return m, spinnerTick()
+
 	case doneMsg:
 		m.err = msg.err
+		m.report = msg.report
 		if msg.err == nil {
 			// Success - show choice
 			m.step = stepChoice
@@ -163,8 +233,9 @@ func (m *model) View() string {
 	switch m.step {
 	case stepProjectName:
 		b.WriteString("Enter project name:\n")
-		b.WriteString(fmt.Sprintf("> %s█\n\n", m.input))
-		b.WriteString("Press Enter to continue")
+		b.WriteString(fmt.Sprintf("> %s█\n", m.input))
+		b.WriteString(RenderNameValidation(m.input, false))
+		b.WriteString("\nPress Enter to continue")
 
 	case stepTemplate:
 		b.WriteString(fmt.Sprintf("Project: %s\n\n", m.projectName))
@@ -172,14 +243,40 @@ func (m *model) View() string {
 		b.WriteString(RenderTemplateList(m.templates, m.cursor))
 		b.WriteString("\nUse ↑/↓ to navigate, Enter to select")
 
+	case stepLicense:
+		b.WriteString(fmt.Sprintf("Project: %s\n", m.projectName))
+		b.WriteString(fmt.Sprintf("Template: %s\n\n", m.templates[m.selected].Name()))
+		b.WriteString("Select license:\n\n")
+		b.WriteString(RenderOptionList(licenseOptions(), m.cursor))
+		headers := " "
+		if m.app.LicenseHeaders {
+			headers = "x"
+		}
+		b.WriteString(fmt.Sprintf("\n[%s] Add SPDX headers to .go files\n", headers))
+		b.WriteString("\nUse ↑/↓ to navigate, h to toggle headers, Enter to create")
+
 	case stepCreating:
-		selected := m.templates[m.cursor]
+		selected := m.templates[m.selected]
 		b.WriteString(fmt.Sprintf("Creating project '%s' with %s...\n\n", m.projectName, selected.Name()))
-		b.WriteString(RenderOutputBox(m.output))
+		b.WriteString(RenderChecklist(m.progress, m.frame))
+		b.WriteString("\n")
+		b.WriteString(RenderOutputBox(m.progress.Output))
 
 	case stepChoice:
-		b.WriteString("✅ Project created successfully!\n\n")
+		if m.report != nil && !m.report.Verified() {
+			b.WriteString("⚠️  Project created, but verification failed\n\n")
+		} else {
+			b.WriteString("✅ Project created successfully!\n\n")
+		}
 		b.WriteString(fmt.Sprintf("📁 Location: %s\n\n", m.projectName))
+		if m.report != nil {
+			b.WriteString(RenderTimings(m.report.Timings, m.report.Total))
+			b.WriteString("\n")
+			if v := RenderVerification(m.report); v != "" {
+				b.WriteString(v)
+				b.WriteString("\n")
+			}
+		}
 		b.WriteString("What would you like to do?\n\n")
 		b.WriteString(RenderChoiceMenu(m.cursor, "Open terminal in project folder", "Exit"))
 		b.WriteString("\nUse ↑/↓ to navigate, Enter to select")
@@ -190,7 +287,9 @@ func (m *model) View() string {
 		}
 	}
 
-	if m.step != stepDone && m.step != stepChoice {
+	if m.step == stepProjectName {
+		b.WriteString("\n\nPress ctrl+c to quit")
+	} else if m.step != stepDone && m.step != stepChoice {
 		b.WriteString("\n\nPress ctrl+c or q to quit")
 	}
 
@@ -199,12 +298,26 @@ func (m *model) View() string {
 
 func (m *model) createProject() tea.Cmd {
 	return func() tea.Msg {
-		tmpl := m.templates[m.cursor]
-		if err := m.app.CreateProject(tmpl, m.projectName); err != nil {
+		tmpl := m.templates[m.selected]
+		report, err := m.app.CreateProject(tmpl, m.projectName)
+		if err != nil {
 			return doneMsg{err: err}
 		}
-		return doneMsg{err: nil}
+		return doneMsg{err: nil, report: report}
+	}
+}
+
+// licenseCursor returns the license row matching app.License
+func (m *model) licenseCursor() int {
+	if m.app.License == nil {
+		return 0
+	}
+	for i, l := range licenses.All() {
+		if l.ID == m.app.License.ID {
+			return i + 1
+		}
 	}
+	return 0
 }
 
 func (m *model) openTerminal() tea.Cmd {
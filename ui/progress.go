@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dlcuy22/endmi/core"
+)
+
+// ProgressPrinter prints concise, line-based creation progress for
+// non-interactive use. Command output is held back and only printed when
+// the command fails.
+type ProgressPrinter struct {
+	w      io.Writer
+	mu     sync.Mutex
+	output map[string][]string
+}
+
+// NewProgressPrinter returns a printer writing to w
+func NewProgressPrinter(w io.Writer) *ProgressPrinter {
+	return &ProgressPrinter{w: w, output: map[string][]string{}}
+}
+
+// Handle is a core.EventHandler
+func (p *ProgressPrinter) Handle(e core.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := strings.Join(e.Command, " ")
+
+	switch e.Kind {
+	case core.EventPhaseStarted:
+		if e.Total > 0 {
+			fmt.Fprintf(p.w, "→ %s (%d)\n", e.Phase, e.Total)
+		} else {
+			fmt.Fprintf(p.w, "→ %s\n", e.Phase)
+		}
+
+	case core.EventPhaseFinished:
+		if e.Err != nil {
+			fmt.Fprintf(p.w, "✗ %s: %v\n", e.Phase, e.Err)
+		} else {
+			fmt.Fprintf(p.w, "✓ %s (%s)\n", e.Phase, e.Duration.Round(time.Millisecond))
+		}
+
+	case core.EventCommandStarted:
+		if e.Total > 0 {
+			fmt.Fprintf(p.w, "  [%d/%d] %s\n", e.Index, e.Total, key)
+		}
+
+	case core.EventOutput:
+		p.output[key] = append(p.output[key], e.Line)
+
+	case core.EventWarning:
+		fmt.Fprintf(p.w, "! %s\n", e.Line)
+
+	case core.EventCommandFinished:
+		lines := p.output[key]
+		delete(p.output, key)
+		if e.Err == nil {
+			return
+		}
+		fmt.Fprintf(p.w, "  $ %s (exit %d)\n", key, e.ExitCode)
+		for _, line := range lines {
+			fmt.Fprintf(p.w, "    %s\n", line)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/dlcuy22/endmi/core"
 	"github.com/dlcuy22/endmi/extensions"
 )
 
@@ -20,6 +23,19 @@ func RenderTemplateList(templates []extensions.Template, cursor int) string {
 	return result
 }
 
+// RenderOptionList renders a selectable list of plain options with the cursor
+func RenderOptionList(options []string, cursor int) string {
+	result := ""
+	for i, option := range options {
+		if cursor == i {
+			result += fmt.Sprintf("\033[48;5;240m\033[97m > %s \033[0m\n", option)
+		} else {
+			result += fmt.Sprintf("   %s\n", option)
+		}
+	}
+	return result
+}
+
 // RenderChoiceMenu renders a two-option choice menu with cursor
 func RenderChoiceMenu(cursor int, option1, option2 string) string {
 	result := ""
@@ -46,3 +62,143 @@ func RenderOutputBox(lines []string) string {
 	result += "╰──────────────────────────────────────────────╯\n"
 	return result
 }
+
+// RenderTimings renders per-step durations followed by the total
+func RenderTimings(timings []core.StepTiming, total time.Duration) string {
+	result := "Timings:\n"
+	for _, t := range timings {
+		result += fmt.Sprintf("  %-14s %s\n", t.Name, t.Duration.Round(time.Millisecond))
+	}
+	result += fmt.Sprintf("  %-14s %s\n", "total", total.Round(time.Millisecond))
+	return result
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// maxOutputLines caps how many command output lines the output box keeps
+const maxOutputLines = 8
+
+// PhaseState is one row of the creation checklist
+type PhaseState struct {
+	Phase    core.Phase
+	Index    int
+	Total    int
+	Done     bool
+	Err      error
+	Duration time.Duration
+}
+
+// Checklist folds creation events into an ordered list of phases and the
+// most recent command output
+type Checklist struct {
+	Phases []PhaseState
+	Output []string
+}
+
+// Apply updates the checklist with a single event
+func (c *Checklist) Apply(e core.Event) {
+	switch e.Kind {
+	case core.EventPhaseStarted:
+		c.Phases = append(c.Phases, PhaseState{Phase: e.Phase, Total: e.Total})
+
+	case core.EventPhaseFinished:
+		if p := c.find(e.Phase); p != nil {
+			p.Done = true
+			p.Err = e.Err
+			p.Duration = e.Duration
+		}
+
+	case core.EventCommandStarted:
+		if p := c.find(e.Phase); p != nil && e.Total > 0 {
+			p.Index = e.Index
+		}
+
+	case core.EventOutput:
+		c.addOutput(e.Line)
+
+	case core.EventWarning:
+		c.addOutput("warning: " + e.Line)
+	}
+}
+
+func (c *Checklist) addOutput(line string) {
+	c.Output = append(c.Output, line)
+	if len(c.Output) > maxOutputLines {
+		c.Output = c.Output[len(c.Output)-maxOutputLines:]
+	}
+}
+
+func (c *Checklist) find(phase core.Phase) *PhaseState {
+	for i := len(c.Phases) - 1; i >= 0; i-- {
+		if c.Phases[i].Phase == phase {
+			return &c.Phases[i]
+		}
+	}
+	return nil
+}
+
+// RenderChecklist renders each phase with a spinner, check or cross
+func RenderChecklist(c Checklist, frame int) string {
+	result := ""
+	for _, p := range c.Phases {
+		label := string(p.Phase)
+		if p.Total > 0 && p.Index > 0 {
+			label += fmt.Sprintf(" (%d/%d)", p.Index, p.Total)
+		} else if p.Total > 0 {
+			label += fmt.Sprintf(" (%d)", p.Total)
+		}
+
+		switch {
+		case !p.Done:
+			result += fmt.Sprintf(" %s %s\n", spinnerFrames[frame%len(spinnerFrames)], label)
+		case p.Err != nil:
+			result += fmt.Sprintf(" \033[31m✗\033[0m %s: %v\n", label, p.Err)
+		default:
+			result += fmt.Sprintf(" \033[32m✓\033[0m %s \033[90m%s\033[0m\n", label, p.Duration.Round(time.Millisecond))
+		}
+	}
+	return result
+}
+
+// RenderVerification renders the post-create check results, or an empty
+// string when verification did not run
+func RenderVerification(report *core.Report) string {
+	if len(report.Verification) == 0 {
+		return ""
+	}
+
+	result := "Verification:\n"
+	for _, v := range report.Verification {
+		mark := "\033[32m✓\033[0m"
+		if !v.Passed {
+			mark = "\033[31m✗\033[0m"
+		}
+		result += fmt.Sprintf("  %s %-6s \033[90m%s\033[0m\n", mark, v.Name, v.Duration.Round(time.Millisecond))
+	}
+	if !report.Verified() {
+		result += fmt.Sprintf("⚠️  Project kept, but verification failed. Output saved to %s\n", report.VerifyLog)
+	}
+	return result
+}
+
+// RenderNameValidation renders live feedback for a project name input. An
+// empty input renders nothing when allowEmpty is set.
+func RenderNameValidation(input string, allowEmpty bool) string {
+	if input == "" {
+		if allowEmpty {
+			return ""
+		}
+		return "\033[90m  enter a name like my-api\033[0m\n"
+	}
+
+	err := core.ValidateProjectName(input)
+	if err == nil {
+		return "\033[32m  ✓ valid name\033[0m\n"
+	}
+
+	var nameErr *core.NameError
+	if errors.As(err, &nameErr) {
+		return fmt.Sprintf("\033[31m  ✗ %s\033[0m \033[90m(suggestion: %s)\033[0m\n", nameErr.Reason, nameErr.Suggestion)
+	}
+	return fmt.Sprintf("\033[31m  ✗ %v\033[0m\n", err)
+}
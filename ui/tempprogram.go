@@ -28,30 +28,33 @@ type tempModel struct {
 	templates   []extensions.Template
 	input       string
 	err         error
-	output      []string
+	progress    Checklist
+	frame       int
 	tcm         *core.TempCodeManager
+	opts        core.TempOptions
 	resultPath  string
+	report      *core.Report
 }
 
-func initialTempModel(tcm *core.TempCodeManager, templates []extensions.Template) tempModel {
+func initialTempModel(tcm *core.TempCodeManager, templates []extensions.Template, opts core.TempOptions) tempModel {
 	return tempModel{
+		opts:      opts,
 		step:      tempStepTemplate,
 		templates: templates,
 		cursor:    0,
 		input:     "",
-		output:    []string{},
 		tcm:       tcm,
 	}
 }
 
 // NewTempProgram creates a Bubble Tea program for temporary project creation
-func NewTempProgram(tcm *core.TempCodeManager, templates []extensions.Template) *tea.Program {
-	m := initialTempModel(tcm, templates)
+func NewTempProgram(tcm *core.TempCodeManager, templates []extensions.Template, opts core.TempOptions) *tea.Program {
+	m := initialTempModel(tcm, templates, opts)
 	p := tea.NewProgram(&m)
 
-	tcm.App.Output = func(line string) {
+	tcm.App.Events = func(e core.Event) {
 		if p != nil {
-			p.Send(outputMsg{line: line})
+			p.Send(eventMsg{event: e})
 		}
 	}
 
@@ -70,16 +73,23 @@ func (m *tempModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.step == tempStepCreating {
 				return m, nil
 			}
+			if msg.String() == "q" && m.step == tempStepProjectName {
+				m.input += "q"
+				return m, nil
+			}
 			return m, tea.Quit
 
 		case "enter":
 			switch m.step {
 			case tempStepProjectName:
 				// Allow empty input for auto-generated name
-				m.step = tempStepTemplate
+				if m.input == "" || core.ValidateProjectName(m.input) == nil {
+					m.projectName = m.input
+					m.step = tempStepTemplate
+				}
 			case tempStepTemplate:
 				m.step = tempStepCreating
-				return m, m.createTempProject()
+				return m, tea.Batch(m.createTempProject(), spinnerTick())
 			case tempStepChoice:
 				if m.cursor == 0 {
 					// Open terminal in temp folder
@@ -126,12 +136,20 @@ func (m *tempModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-	case outputMsg:
-		m.output = append(m.output, msg.line)
+	case eventMsg:
+		m.progress.Apply(msg.event)
 		return m, nil
 
+	case tickMsg:
+		if m.step != tempStepCreating {
+			return m, nil
+		}
+		m.frame++
+		return m, spinnerTick()
+
 	case doneMsg:
 		m.err = msg.err
+		m.report = msg.report
 		if msg.err == nil {
 			// Success - show choice
 			m.step = tempStepChoice
@@ -155,8 +173,9 @@ func (m *tempModel) View() string {
 	switch m.step {
 	case tempStepProjectName:
 		b.WriteString("Enter project name (leave empty for auto-generated):\n")
-		b.WriteString(fmt.Sprintf("> %s█\n\n", m.input))
-		b.WriteString("Press Enter to continue or Tab to go back")
+		b.WriteString(fmt.Sprintf("> %s█\n", m.input))
+		b.WriteString(RenderNameValidation(m.input, true))
+		b.WriteString("\nPress Enter to continue or Tab to go back")
 
 	case tempStepTemplate:
 		if m.projectName != "" && m.input != "" {
@@ -175,11 +194,25 @@ func (m *tempModel) View() string {
 			projectDisplayName = "[auto-generated]"
 		}
 		b.WriteString(fmt.Sprintf("Creating temporary project '%s' with %s...\n\n", projectDisplayName, selected.Name()))
-		b.WriteString(RenderOutputBox(m.output))
+		b.WriteString(RenderChecklist(m.progress, m.frame))
+		b.WriteString("\n")
+		b.WriteString(RenderOutputBox(m.progress.Output))
 
 	case tempStepChoice:
-		b.WriteString("✅ Temporary project created successfully!\n\n")
+		if m.report != nil && !m.report.Verified() {
+			b.WriteString("⚠️  Temporary project created, but verification failed\n\n")
+		} else {
+			b.WriteString("✅ Temporary project created successfully!\n\n")
+		}
 		b.WriteString(fmt.Sprintf("📁 Location: %s\n\n", m.resultPath))
+		if m.report != nil {
+			b.WriteString(RenderTimings(m.report.Timings, m.report.Total))
+			b.WriteString("\n")
+			if v := RenderVerification(m.report); v != "" {
+				b.WriteString(v)
+				b.WriteString("\n")
+			}
+		}
 		b.WriteString("What would you like to do?\n\n")
 		b.WriteString(RenderChoiceMenu(m.cursor, "Open terminal in temp folder", "Exit"))
 		b.WriteString("\nUse ↑/↓ to navigate, Enter to select")
@@ -190,7 +223,9 @@ func (m *tempModel) View() string {
 		}
 	}
 
-	if m.step != tempStepDone && m.step != tempStepChoice {
+	if m.step == tempStepProjectName {
+		b.WriteString("\n\nPress ctrl+c to quit")
+	} else if m.step != tempStepDone && m.step != tempStepChoice {
 		b.WriteString("\n\nPress ctrl+c or q to quit")
 	}
 
@@ -200,12 +235,12 @@ func (m *tempModel) View() string {
 func (m *tempModel) createTempProject() tea.Cmd {
 	return func() tea.Msg {
 		tmpl := m.templates[m.cursor]
-		projectPath, err := m.tcm.CreateTempProject(tmpl, m.input)
+		report, err := m.tcm.CreateTempProject(tmpl, m.input, m.opts)
 		if err != nil {
 			return doneMsg{err: err}
 		}
-		m.resultPath = projectPath
-		return doneMsg{err: nil}
+		m.resultPath = report.Path
+		return doneMsg{err: nil, report: report}
 	}
 }
 
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"3d", 3 * day, false},
+		{"2w", 14 * day, false},
+		{"1d12h", day + 12*time.Hour, false},
+		{"1w2d", 9 * day, false},
+		{"1w2d3h4m", 9*day + 3*time.Hour + 4*time.Minute, false},
+		{"0d", 0, false},
+		{" 3d ", 3 * day, false},
+		{"", 0, true},
+		{"   ", 0, true},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"1.5d", 0, true},
+		{"2d1w", 0, true},
+		{"3days", 0, true},
+		{"abc", 0, true},
+		{"10", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -15,6 +16,34 @@ const (
 // Config represents the structure of endmi.json
 type Config struct {
 	TempDir string `json:"TempDir"`
+	// GoBinary is an optional go binary, Go root or version (e.g. "1.22")
+	// to use instead of the go found on PATH.
+	GoBinary string `json:"GoBinary,omitempty"`
+	// Verify runs build, vet and test on every new project by default.
+	Verify bool `json:"Verify,omitempty"`
+	// Author is the copyright holder for generated LICENSE files. Defaults
+	// to git's user.name.
+	Author string `json:"Author,omitempty"`
+	// License is the default license ID (e.g. "MIT") for new projects.
+	License string `json:"License,omitempty"`
+	// LicenseHeaders adds SPDX headers to generated .go files by default.
+	LicenseHeaders bool `json:"LicenseHeaders,omitempty"`
+	// Vendor runs `go mod vendor` on every new project by default.
+	Vendor bool `json:"Vendor,omitempty"`
+	// TempTTL is how long temp projects live before `temp gc` removes them,
+	// e.g. "7d". Empty means they never expire.
+	TempTTL string `json:"TempTTL,omitempty"`
+	// TempAutoGC runs `temp gc` every time endmi starts.
+	TempAutoGC bool `json:"TempAutoGC,omitempty"`
+	// TempQuota caps the temp workspace's disk usage, e.g. "2GB". Empty
+	// means no quota.
+	TempQuota string `json:"TempQuota,omitempty"`
+	// TempQuotaMode is "warn" (default) or "refuse": what `temp create`
+	// does when the workspace is over TempQuota.
+	TempQuotaMode string `json:"TempQuotaMode,omitempty"`
+	// TempScratch runs snippets as packages of one shared scratch module,
+	// skipping `go mod init` and `go mod tidy` for each of them.
+	TempScratch bool `json:"TempScratch,omitempty"`
 }
 
 // getHomeDir resolves the user's home directory.
@@ -100,6 +129,30 @@ func WriteConfig() error {
 	return os.WriteFile(configPath, data, 0644)
 }
 
+// LoadConfig reads and parses ~/.endmi/endmi.json
+func LoadConfig() (*Config, error) {
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	return LoadConfigFrom(configPath)
+}
+
+// LoadConfigFrom reads and parses a config file at an arbitrary path
+func LoadConfigFrom(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
 // CheckConfigExists returns true if ~/.endmi/endmi.json exists
 func CheckConfigExists() (bool, error) {
 	configPath, err := GetConfigFilePath()
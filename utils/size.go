@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a byte size such as "512MB", "2GB" or "1.5G". Units are
+// binary (1KB = 1024 bytes); a bare number is bytes
+func ParseSize(s string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	if num == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			num = strings.TrimSpace(strings.TrimSuffix(num, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 || math.IsNaN(n) || n*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB, 2GB)", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// FormatSize renders a byte count in the largest sensible unit, e.g.
+// "1.2 GB" or "340 KB"
+func FormatSize(n int64) string {
+	for _, u := range sizeUnits[:4] {
+		if n >= u.size {
+			return fmt.Sprintf("%.1f %s", float64(n)/float64(u.size), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"1K", 1 << 10, false},
+		{"1KB", 1 << 10, false},
+		{"500MB", 500 << 20, false},
+		{"2GB", 2 << 30, false},
+		{"1.5G", 3 << 29, false},
+		{"1TB", 1 << 40, false},
+		{"2gb", 2 << 30, false},
+		{" 2 GB ", 2 << 30, false},
+		{"", 0, true},
+		{"GB", 0, true},
+		{"-1GB", 0, true},
+		{"2XB", 0, true},
+		{"two GB", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"1e300GB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
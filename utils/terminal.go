@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 )
@@ -66,3 +67,15 @@ func OpenTerminalInDirectory(dir string) error {
 
 	return nil
 }
+
+// IsInteractive reports whether stdin and stdout are both terminals, so
+// endmi can prompt instead of failing
+func IsInteractive() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}